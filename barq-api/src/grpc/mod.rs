@@ -3,8 +3,12 @@ use crate::AppState;
 use barq_proto::barq::barq_server::Barq;
 use barq_proto::barq::{
     CreateCollectionRequest, CreateCollectionResponse, 
-    HealthRequest, HealthResponse, 
+    HealthRequest, HealthResponse, ComponentStatus,
     InsertDocumentRequest, InsertDocumentResponse, 
+    BatchInsertRequest, BatchInsertResponse,
+    DeleteDocumentRequest, BatchDeleteResponse,
+    GetDocumentRequest, GetDocumentResponse,
+    ListCollectionsRequest, ListCollectionsResponse, CollectionSummary,
     SearchRequest, SearchResponse, SearchResult,
     BatchSearchRequest, BatchSearchResponse, QueryResults
 };
@@ -22,6 +26,42 @@ impl GrpcService {
     }
 }
 
+/// Optional capabilities advertised in HealthResponse.features.
+const FEATURES: &[&str] = &["binary_payload", "wait_for_index"];
+
+fn parse_document_id(id: &str) -> DocumentId {
+    if let Ok(u) = id.parse::<u64>() {
+        DocumentId::U64(u)
+    } else {
+        DocumentId::Str(id.to_string())
+    }
+}
+
+fn document_id_string(id: DocumentId) -> String {
+    match id {
+        DocumentId::U64(v) => v.to_string(),
+        DocumentId::Str(s) => s,
+    }
+}
+
+/// Builds the document of an insert, reading the bytes payload in preference
+/// to payload_json as FEATURES promises. Inserts are indexed before they are
+/// acknowledged, so wait_for_index holds without any handling of its own.
+fn document_from_request(req: InsertDocumentRequest) -> Result<Document, Status> {
+    let payload_json: serde_json::Value = if req.payload.is_empty() {
+        serde_json::from_str(&req.payload_json)
+    } else {
+        serde_json::from_slice(&req.payload)
+    }
+    .map_err(|e| Status::invalid_argument(format!("Invalid JSON payload: {}", e)))?;
+
+    Ok(Document {
+        id: parse_document_id(&req.id),
+        vector: req.vector,
+        payload: Some(json_to_payload(payload_json)),
+    })
+}
+
 fn json_to_payload(v: serde_json::Value) -> PayloadValue {
     match v {
         serde_json::Value::Null => PayloadValue::Null,
@@ -51,9 +91,23 @@ fn json_to_payload(v: serde_json::Value) -> PayloadValue {
 #[tonic::async_trait]
 impl Barq for GrpcService {
     async fn health(&self, _request: Request<HealthRequest>) -> Result<Response<HealthResponse>, Status> {
+        // A health check must not queue behind a long write such as an
+        // index rebuild, so a held lock is reported rather than waited on.
+        let tenant = barq_core::TenantId::from("default");
+        let storage_detail = match self.state.storage.try_lock() {
+            Ok(storage) => format!("{} collections", storage.catalog().collection_names(&tenant).len()),
+            Err(_) => "busy".to_string(),
+        };
+
         Ok(Response::new(HealthResponse {
             ok: true,
             version: env!("CARGO_PKG_VERSION").to_string(),
+            components: vec![ComponentStatus {
+                name: "storage".to_string(),
+                ok: true,
+                detail: storage_detail,
+            }],
+            features: FEATURES.iter().map(|f| f.to_string()).collect(),
         }))
     }
 
@@ -100,27 +154,10 @@ impl Barq for GrpcService {
         &self,
         request: Request<InsertDocumentRequest>,
     ) -> Result<Response<InsertDocumentResponse>, Status> {
-        let req = request.into_inner();
-        
-        let payload_json: serde_json::Value = serde_json::from_str(&req.payload_json)
-            .map_err(|e| Status::invalid_argument(format!("Invalid JSON payload: {}", e)))?;
-        
-        let doc_id = if let Ok(u) = req.id.parse::<u64>() {
-            DocumentId::U64(u)
-        } else {
-            DocumentId::Str(req.id.clone())
-        };
-
-        let collection_name = req.collection;
+        let mut req = request.into_inner();
+        let collection_name = std::mem::take(&mut req.collection);
         let tenant = barq_core::TenantId::from("default");
-        
-        let payload = json_to_payload(payload_json);
-
-        let doc = Document {
-            id: doc_id,
-            vector: req.vector,
-            payload: Some(payload),
-        };
+        let doc = document_from_request(req)?;
 
         self.state.ensure_primary_for_document(&tenant, &doc.id).map_err(|e| Status::failed_precondition(e.to_string()))?;
 
@@ -131,6 +168,102 @@ impl Barq for GrpcService {
         }
     }
 
+    async fn batch_insert(
+        &self,
+        request: Request<BatchInsertRequest>,
+    ) -> Result<Response<BatchInsertResponse>, Status> {
+        let req = request.into_inner();
+        let tenant = barq_core::TenantId::from("default");
+
+        let mut storage = self.state.storage.lock().await;
+        let mut inserted = 0;
+        for doc_req in req.documents {
+            let doc = document_from_request(doc_req)?;
+            self.state.ensure_primary_for_document(&tenant, &doc.id).map_err(|e| Status::failed_precondition(e.to_string()))?;
+            storage.insert_for_tenant(&tenant, &req.collection, doc, false)
+                .map_err(|e| Status::internal(e.to_string()))?;
+            inserted += 1;
+        }
+
+        Ok(Response::new(BatchInsertResponse { inserted }))
+    }
+
+    async fn batch_delete(
+        &self,
+        request: Request<tonic::Streaming<DeleteDocumentRequest>>,
+    ) -> Result<Response<BatchDeleteResponse>, Status> {
+        let mut stream = request.into_inner();
+        let tenant = barq_core::TenantId::from("default");
+
+        let mut deleted = 0;
+        while let Some(req) = stream.message().await? {
+            let doc_id = parse_document_id(&req.id);
+            self.state.ensure_primary_for_document(&tenant, &doc_id).map_err(|e| Status::failed_precondition(e.to_string()))?;
+            // Locked per message so a slow client does not hold up others.
+            let mut storage = self.state.storage.lock().await;
+            let removed = storage.delete_for_tenant(&tenant, &req.collection, doc_id)
+                .map_err(|e| Status::internal(e.to_string()))?;
+            if removed {
+                deleted += 1;
+            }
+        }
+
+        Ok(Response::new(BatchDeleteResponse { deleted }))
+    }
+
+    async fn get_document(
+        &self,
+        request: Request<GetDocumentRequest>,
+    ) -> Result<Response<GetDocumentResponse>, Status> {
+        let req = request.into_inner();
+        let tenant = barq_core::TenantId::from("default");
+
+        let storage = self.state.storage.lock().await;
+        let collection = storage.catalog().collection(&tenant, &req.collection)
+            .map_err(|e| Status::not_found(e.to_string()))?;
+
+        let doc = match collection.get(&parse_document_id(&req.id)) {
+            Some(doc) => doc,
+            None => return Ok(Response::new(GetDocumentResponse::default())),
+        };
+        let payload = match &doc.payload {
+            Some(payload) => serde_json::to_vec(payload).map_err(|e| Status::internal(e.to_string()))?,
+            None => Vec::new(),
+        };
+
+        Ok(Response::new(GetDocumentResponse {
+            found: true,
+            id: document_id_string(doc.id),
+            vector: doc.vector,
+            payload_json: String::new(),
+            payload,
+        }))
+    }
+
+    async fn list_collections(
+        &self,
+        _request: Request<ListCollectionsRequest>,
+    ) -> Result<Response<ListCollectionsResponse>, Status> {
+        let tenant = barq_core::TenantId::from("default");
+
+        let storage = self.state.storage.lock().await;
+        let mut names = storage.catalog().collection_names(&tenant);
+        names.sort();
+
+        let mut collections = Vec::new();
+        for name in names {
+            let collection = storage.catalog().collection(&tenant, &name)
+                .map_err(|e| Status::internal(e.to_string()))?;
+            let (dimension, metric) = match collection.schema().vector_config() {
+                Some((dimension, metric, _)) => (dimension as u32, format!("{:?}", metric)),
+                None => (0, String::new()),
+            };
+            collections.push(CollectionSummary { name, dimension, metric });
+        }
+
+        Ok(Response::new(ListCollectionsResponse { collections }))
+    }
+
     async fn search(
         &self,
         request: Request<SearchRequest>,
@@ -149,13 +282,8 @@ impl Barq for GrpcService {
 
         let mut proto_results = Vec::new();
         for res in results {
-            let id_str = match res.id {
-                DocumentId::U64(v) => v.to_string(),
-                DocumentId::Str(s) => s,
-            };
-            
             proto_results.push(SearchResult {
-                id: id_str,
+                id: document_id_string(res.id),
                 score: res.score,
                 payload_json: "{}".to_string(), 
             });
@@ -194,12 +322,8 @@ impl Barq for GrpcService {
         for batch in results_vec {
              let mut hits = Vec::new();
              for res in batch {
-                let id_str = match res.id {
-                    DocumentId::U64(v) => v.to_string(),
-                    DocumentId::Str(s) => s,
-                };
                 hits.push(SearchResult {
-                    id: id_str,
+                    id: document_id_string(res.id),
                     score: res.score,
                     payload_json: "{}".to_string(),
                 });
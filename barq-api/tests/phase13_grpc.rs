@@ -2,7 +2,10 @@ use barq_api::{AppState, ApiAuth, ClusterConfig, ClusterRouter};
 use barq_api::grpc::GrpcService;
 use barq_proto::barq::barq_server::BarqServer;
 use barq_proto::barq::barq_client::BarqClient;
-use barq_proto::barq::{CreateCollectionRequest, InsertDocumentRequest, SearchRequest, HealthRequest};
+use barq_proto::barq::{
+    BatchInsertRequest, CreateCollectionRequest, DeleteDocumentRequest, GetDocumentRequest,
+    HealthRequest, InsertDocumentRequest, ListCollectionsRequest, SearchRequest,
+};
 use barq_storage::Storage;
 use tonic::transport::Server;
 use std::net::SocketAddr;
@@ -49,6 +52,9 @@ async fn test_grpc_health() {
     let response = client.health(request).await.expect("health check failed");
     
     assert!(response.get_ref().ok);
+    assert!(response.get_ref().features.iter().any(|f| f == "binary_payload"));
+    assert!(response.get_ref().features.iter().any(|f| f == "wait_for_index"));
+    assert!(response.get_ref().components.iter().any(|c| c.name == "storage" && c.ok));
     
     tx.send(()).unwrap();
     handle.await.unwrap();
@@ -75,6 +81,7 @@ async fn test_grpc_create_insert_search() {
         id: "doc1".to_string(),
         vector: vec![1.0, 0.0],
         payload_json: "{\"test\": \"ok\"}".to_string(),
+        ..Default::default()
     };
     let _ = client.insert_document(insert_req).await.expect("insert failed");
 
@@ -95,3 +102,71 @@ async fn test_grpc_create_insert_search() {
     tx.send(()).unwrap();
     handle.await.unwrap();
 }
+
+#[tokio::test]
+async fn test_grpc_batch_get_list_delete() {
+    let (addr, handle, tx) = start_test_grpc_server().await;
+
+    let dst = format!("http://{}", addr);
+    let mut client = BarqClient::connect(dst).await.expect("failed to connect");
+
+    client.create_collection(CreateCollectionRequest {
+        name: "grpc_batch".to_string(),
+        dimension: 2,
+        metric: "L2".to_string(),
+    }).await.expect("create collection failed");
+
+    // The bytes payload is read in preference to payload_json.
+    let documents = vec![
+        InsertDocumentRequest {
+            id: "1".to_string(),
+            vector: vec![1.0, 0.0],
+            payload: b"{\"n\": 1}".to_vec(),
+            ..Default::default()
+        },
+        InsertDocumentRequest {
+            id: "doc-2".to_string(),
+            vector: vec![0.0, 1.0],
+            payload_json: "{\"n\": 2}".to_string(),
+            ..Default::default()
+        },
+    ];
+    let inserted = client.batch_insert(BatchInsertRequest {
+        collection: "grpc_batch".to_string(),
+        documents,
+    }).await.expect("batch insert failed").into_inner().inserted;
+    assert_eq!(inserted, 2);
+
+    let got = client.get_document(GetDocumentRequest {
+        collection: "grpc_batch".to_string(),
+        id: "1".to_string(),
+    }).await.expect("get failed").into_inner();
+    assert!(got.found);
+    assert_eq!(got.vector, vec![1.0, 0.0]);
+    let payload: serde_json::Value = serde_json::from_slice(&got.payload).unwrap();
+    assert_eq!(payload, serde_json::json!({"n": 1}));
+
+    let collections = client.list_collections(ListCollectionsRequest {})
+        .await.expect("list failed").into_inner().collections;
+    assert_eq!(collections.len(), 1);
+    assert_eq!(collections[0].name, "grpc_batch");
+    assert_eq!(collections[0].dimension, 2);
+    assert_eq!(collections[0].metric, "L2");
+
+    let deletes = ["1", "doc-2", "missing"].iter().map(|id| DeleteDocumentRequest {
+        collection: "grpc_batch".to_string(),
+        id: id.to_string(),
+    }).collect::<Vec<_>>();
+    let deleted = client.batch_delete(tokio_stream::iter(deletes))
+        .await.expect("batch delete failed").into_inner().deleted;
+    assert_eq!(deleted, 2);
+
+    let gone = client.get_document(GetDocumentRequest {
+        collection: "grpc_batch".to_string(),
+        id: "doc-2".to_string(),
+    }).await.expect("get failed").into_inner();
+    assert!(!gone.found);
+
+    tx.send(()).unwrap();
+    handle.await.unwrap();
+}
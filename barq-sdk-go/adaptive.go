@@ -0,0 +1,84 @@
+package barq
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// aimdLimiter caps in-flight inserts at a batch size, its limit, adjusted
+// per round.
+type aimdLimiter struct {
+	mu sync.Mutex
+	// wake is closed, and replaced, whenever an insert finishes, waking
+	// every acquire waiting for a slot.
+	wake     chan struct{}
+	limit    int
+	max      int
+	inflight int
+
+	// The round in progress, and the best round mean latency so far.
+	done     int
+	failed   bool
+	elapsed  time.Duration
+	baseline time.Duration
+}
+
+func newAIMDLimiter(start, max int) *aimdLimiter {
+	if start > max {
+		start = max
+	}
+	return &aimdLimiter{wake: make(chan struct{}), limit: start, max: max}
+}
+
+// acquire waits for a slot under the current limit. It gives up with the
+// context's error once ctx is done, without taking a slot.
+func (l *aimdLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inflight < l.limit {
+			l.inflight++
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// release records one finished insert and, at the end of a round, adjusts
+// the limit.
+func (l *aimdLimiter) release(latency time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	l.done++
+	l.elapsed += latency
+	if err != nil {
+		l.failed = true
+	}
+
+	if l.done >= l.limit {
+		mean := l.elapsed / time.Duration(l.done)
+		if l.baseline == 0 || mean < l.baseline {
+			l.baseline = mean
+		}
+		if l.failed || mean > 2*l.baseline {
+			l.limit /= 2
+			if l.limit < 1 {
+				l.limit = 1
+			}
+		} else if l.limit < l.max {
+			l.limit++
+		}
+		l.done, l.failed, l.elapsed = 0, false, 0
+	}
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
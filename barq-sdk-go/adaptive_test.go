@@ -0,0 +1,106 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAIMDLimiterAcquireHonorsContext(t *testing.T) {
+	l := newAIMDLimiter(1, 1)
+	if err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v while the only slot was taken, want the context's error", err)
+	}
+	if l.inflight != 1 {
+		t.Fatalf("inflight is %d after a cancelled acquire, want 1", l.inflight)
+	}
+}
+
+func TestAIMDLimiterReleaseWakesWaiter(t *testing.T) {
+	l := newAIMDLimiter(1, 1)
+	if err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	acquired := make(chan error, 1)
+	go func() { acquired <- l.acquire(context.Background()) }()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("acquire returned %v before a slot was free", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	l.release(time.Millisecond, nil)
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire still waiting after release")
+	}
+}
+
+func TestAIMDLimiterHalvesOnFailure(t *testing.T) {
+	l := newAIMDLimiter(2, 8)
+	for i := 0; i < 2; i++ {
+		l.acquire(context.Background())
+	}
+	l.release(time.Millisecond, nil)
+	l.release(time.Millisecond, nil)
+	if l.limit != 3 {
+		t.Fatalf("limit is %d after a clean round, want 3", l.limit)
+	}
+	for i := 0; i < 3; i++ {
+		l.acquire(context.Background())
+	}
+	l.release(time.Millisecond, nil)
+	l.release(time.Millisecond, errors.New("unavailable"))
+	l.release(time.Millisecond, nil)
+	if l.limit != 1 {
+		t.Fatalf("limit is %d after a failed round, want 1", l.limit)
+	}
+}
+
+func TestInsertConcurrentAdaptiveStaysUnderMaxInFlight(t *testing.T) {
+	var inflight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{BaseURL: srv.URL})
+	reqs := make([]InsertRequest, 40)
+	for i := range reqs {
+		reqs[i] = InsertRequest{ID: i, Vector: []float32{1, 2}}
+	}
+	report, err := c.InsertConcurrent(context.Background(), "docs", reqs,
+		WithImportConcurrency(1), WithConcurrencyOptions(ConcurrencyOptions{Adaptive: true, MaxInFlight: 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Succeeded != len(reqs) {
+		t.Fatalf("got %+v, want all %d imported", report, len(reqs))
+	}
+	if p := atomic.LoadInt32(&peak); p < 2 || p > 3 {
+		t.Fatalf("peak in-flight inserts was %d, want the batch to grow from 1 to at most 3", p)
+	}
+}
@@ -4,71 +4,409 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/YASSERRMD/barq-db/barq-sdk-go/proto/barq"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+// DefaultTimeout bounds calls whose context carries no deadline.
+const DefaultTimeout = 10 * time.Second
+
+// DefaultConnectTimeout is used when Config.ConnectTimeout is zero.
+const DefaultConnectTimeout = 5 * time.Second
+
+// DefaultAuthHeader is the header, and gRPC metadata key, the API key is
+// sent in unless configured otherwise.
+const DefaultAuthHeader = "x-api-key"
+
+func authValue(scheme, key string) string {
+	if scheme == "" {
+		return key
+	}
+	return scheme + " " + key
+}
+
 type Config struct {
 	BaseURL string
 	APIKey  string
+	// AuthHeader is the header APIKey is sent in; empty means
+	// DefaultAuthHeader. AuthScheme, if set, prefixes the key, so
+	// AuthHeader "Authorization" with AuthScheme "Bearer" sends
+	// "Authorization: Bearer <key>".
+	AuthHeader string
+	AuthScheme string
+	// Timeout is applied with context.WithTimeout to calls whose context
+	// has no deadline of its own; a caller-supplied deadline always wins.
+	// Zero means DefaultTimeout and a negative value disables the default.
+	Timeout time.Duration
+	// ConnectTimeout bounds establishing a connection: DNS lookup and TCP
+	// connect (net.Dialer.Timeout) and, separately, the TLS handshake
+	// (http.Transport.TLSHandshakeTimeout). Time spent connecting still
+	// counts against Timeout. Zero means DefaultConnectTimeout and a
+	// negative value disables it.
+	ConnectTimeout time.Duration
+	// IdleConnTimeout is how long an idle pooled connection is kept before
+	// the client closes it (http.Transport.IdleConnTimeout). Keep it below
+	// the idle timeout of any load balancer in front of the server, so
+	// connections are closed by the client rather than reset under a
+	// request. Zero keeps http.DefaultTransport's 90s and a negative value
+	// keeps idle connections indefinitely.
+	IdleConnTimeout time.Duration
+	// KeepWarmInterval, if positive, sends GET /health that often from a
+	// background goroutine, so a pooled connection is never idle long
+	// enough to be dropped. Close stops it.
+	KeepWarmInterval time.Duration
+	// MaxResponseBytes caps how much of a response body is buffered in
+	// memory, whether or not the server sends a Content-Length. Zero means
+	// no limit. Streaming methods such as SearchEach and ListDocumentsEach
+	// decode incrementally and are not subject to it.
+	MaxResponseBytes int64
+	// MaxPayloadBytes caps the size of each document payload decoded from a
+	// response, and MaxPayloadDepth the nesting depth of arrays and objects
+	// in a decoded response, counted from its top level. Exceeding them
+	// returns ErrPayloadTooLarge or ErrPayloadTooDeep. Zero means
+	// DefaultMaxPayloadBytes and DefaultMaxPayloadDepth; a negative value
+	// disables the limit.
+	MaxPayloadBytes int64
+	MaxPayloadDepth int
+	// ValidateFilters checks, before sending a search, that every field a
+	// filter references is indexed in the collection's schema, failing
+	// with ErrUnindexedFilterField otherwise. Off by default.
+	ValidateFilters bool
+	// BinaryVectors sends insert vectors as base64 little-endian float32
+	// (see EncodeVector) instead of JSON arrays, which is smaller and
+	// cheaper to encode. The server must support vector_encoding.
+	BinaryVectors bool
+	// VectorPrecision rounds insert vector components to that many
+	// significant digits before JSON encoding (see RoundVector), trading a
+	// little precision for smaller requests. Zero sends full precision. It
+	// has no effect with BinaryVectors.
+	VectorPrecision int
+	// MetadataTTL is how long collection metadata (dimension, metric, text
+	// fields) learned from CreateCollection or DescribeCollection is reused
+	// by validation and score normalization. Zero means DefaultMetadataTTL;
+	// a negative value caches until RefreshCollectionMeta.
+	MetadataTTL time.Duration
+	// Gzip compresses request bodies of at least GzipMinBytes (zero means
+	// DefaultGzipMinBytes) and sends them with Content-Encoding: gzip. It
+	// is GzipOff by default; see GzipMode.
+	Gzip         GzipMode
+	GzipMinBytes int
+	// Retry enables retries of failed requests; nil disables them. See
+	// RetryConfig for which failures are retried.
+	Retry *RetryConfig
+	// FallbackURLs are tried in order when a request to BaseURL fails
+	// with the server unreachable or unavailable, after its retries. The
+	// endpoint that last answered is tried first by later requests.
+	FallbackURLs []string
+	// CollectionNamePattern is the rule collection names are checked
+	// against before any request, failing with ErrInvalidCollectionName;
+	// nil means DefaultCollectionNamePattern: up to 128 letters, digits,
+	// dots, underscores and hyphens. Names are URL-escaped in paths either
+	// way.
+	CollectionNamePattern *regexp.Regexp
+	// DefaultTopK is the TopK of searches that leave it zero. A TopK set
+	// on the request always wins. With neither set, searches fail before
+	// sending rather than asking for zero results.
+	DefaultTopK int
+	// Embedder embeds the query text of SearchText. When
+	// EmbeddingCacheSize is positive, the embeddings of that many recent
+	// texts are cached, each for EmbeddingCacheTTL (zero means until
+	// evicted). The cache is off by default.
+	Embedder           Embedder
+	EmbeddingCacheSize int
+	EmbeddingCacheTTL  time.Duration
+	// DimensionPolicy decides what happens to a search vector whose length
+	// does not match the collection's dimension, when that dimension is
+	// cached. The default, DimensionStrict, fails before the request.
+	DimensionPolicy DimensionPolicy
+	// Warn receives warnings such as DimensionPadTruncate adjustments; nil
+	// means the standard log package.
+	Warn func(msg string)
+	// ContextHeaders lists context values sent as headers on every
+	// request, for tenant IDs or tracing baggage set by upstream
+	// middleware. It is empty by default.
+	ContextHeaders []ContextHeader
+	// EnforceReadOnly fails writes to collections whose cached metadata
+	// marks them read-only with ErrReadOnly, before anything is sent.
+	// Collections without cached metadata are left to the server.
+	EnforceReadOnly bool
+	// StrictDecode fails responses that carry fields the SDK's types do
+	// not know with ErrUnknownField, instead of dropping them, to catch
+	// server schema changes in tests. It is off by default, so newer
+	// servers keep working with older SDKs. Payloads are never affected.
+	StrictDecode bool
 }
 
 type Client struct {
 	config Config
 	http   *http.Client
+	// gzipSupport, softDeleteSupport and txnSupport cache whether the
+	// server advertises gzip requests (for GzipIfSupported), soft deletes
+	// and transactions.
+	gzipSupport       featureProbe
+	softDeleteSupport featureProbe
+	txnSupport        featureProbe
+	meta              collectionCache
+	stats             clientStats
+	embeddings        *embeddingCache
+	// activeEndpoint indexes endpoints(): the URL requests go to first.
+	activeEndpoint int32
+	// stopKeepWarm ends the KeepWarmInterval goroutine; see Close.
+	stopKeepWarm chan struct{}
+	closeOnce    sync.Once
 }
 
 func NewClient(config Config) *Client {
-	return &Client{
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MetadataTTL == 0 {
+		config.MetadataTTL = DefaultMetadataTTL
+	}
+	if config.AuthHeader == "" {
+		config.AuthHeader = DefaultAuthHeader
+	}
+	if config.ConnectTimeout == 0 {
+		config.ConnectTimeout = DefaultConnectTimeout
+	}
+	if config.GzipMinBytes == 0 {
+		config.GzipMinBytes = DefaultGzipMinBytes
+	}
+	if config.MaxPayloadBytes == 0 {
+		config.MaxPayloadBytes = DefaultMaxPayloadBytes
+	}
+	if config.MaxPayloadDepth == 0 {
+		config.MaxPayloadDepth = DefaultMaxPayloadDepth
+	}
+	c := &Client{
 		config: config,
-		http: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		http:   &http.Client{Transport: newTransport(config.ConnectTimeout, config.IdleConnTimeout)},
+		meta:   collectionCache{ttl: config.MetadataTTL},
+	}
+	if config.EmbeddingCacheSize > 0 {
+		c.embeddings = newEmbeddingCache(config.EmbeddingCacheSize, config.EmbeddingCacheTTL)
 	}
+	if config.KeepWarmInterval > 0 {
+		c.stopKeepWarm = make(chan struct{})
+		go c.keepWarm(config.KeepWarmInterval)
+	}
+	return c
 }
 
-func (c *Client) request(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
-	url := fmt.Sprintf("%s%s", strings.TrimRight(c.config.BaseURL, "/"), path)
-	
-	var bodyReader io.Reader
+func (c *Client) warn(msg string) {
+	if c.config.Warn != nil {
+		c.config.Warn(msg)
+		return
+	}
+	log.Print(msg)
+}
+
+// newTransport is http.DefaultTransport with its dial and TLS handshake
+// timeouts replaced by connectTimeout, and its idle timeout by idleTimeout
+// when that is set.
+func newTransport(connectTimeout, idleTimeout time.Duration) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	switch {
+	case idleTimeout < 0:
+		t.IdleConnTimeout = 0
+	case idleTimeout > 0:
+		t.IdleConnTimeout = idleTimeout
+	}
+	if connectTimeout < 0 {
+		connectTimeout = 0
+	}
+	t.DialContext = (&net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	t.TLSHandshakeTimeout = connectTimeout
+	return t
+}
+
+// withDefaultTimeout applies timeout to ctx unless ctx already has a
+// deadline or timeout is not positive.
+func withDefaultTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancelOnClose releases a request's default-timeout context once the
+// caller is done with the response body.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (c *Client) request(ctx context.Context, method, path string, body interface{}, opts ...CallOption) ([]byte, error) {
+	resp, err := c.do(ctx, method, path, body, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return c.readBody(resp)
+}
+
+// do sends the request, retrying per Config.Retry, and returns the response
+// with its body unread. Error statuses are converted to *APIError and their
+// body is consumed.
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, opts ...CallOption) (*http.Response, error) {
+	o := newCallOptions(opts)
+
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
+		var err error
+		data, err = json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
-		bodyReader = bytes.NewBuffer(data)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	ctx, cancel := withDefaultTimeout(ctx, c.config.Timeout)
+	data, gzipped, err := c.compressBody(ctx, data)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
+	start := time.Now()
+	endpoints := c.endpoints()
+	first := int(atomic.LoadInt32(&c.activeEndpoint))
+	for i := 0; ; i++ {
+		n := (first + i) % len(endpoints)
+		resp, err := c.hedged(ctx, endpoints[n], method, path, data, gzipped, o, start)
+		if err == nil {
+			if n != first {
+				c.setActiveEndpoint(first, n)
+			}
+			resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if i+1 >= len(endpoints) || !canFailover(method, o.read || o.idempotent || o.idempotencyKey != "", err) {
+			cancel()
+			return nil, err
+		}
+	}
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.config.APIKey)
+// attempts sends the request to baseURL, retrying per Config.Retry.
+func (c *Client) attempts(ctx context.Context, baseURL, method, path string, data []byte, gzipped bool, o *callOptions, start time.Time) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, baseURL, method, path, data, gzipped, o)
+		if err == nil {
+			return resp, nil
+		}
 
-	resp, err := c.http.Do(req)
+		retry := c.config.Retry
+		if retry == nil || attempt >= retry.MaxAttempts || !retry.shouldRetry(method, path, o.read || o.idempotent || o.idempotencyKey != "", err) {
+			return nil, err
+		}
+		wait := retry.delay(attempt)
+		if !retry.withinBudget(ctx, start, wait) || sleepCtx(ctx, wait) != nil {
+			return nil, err
+		}
+	}
+}
+
+// send performs a single attempt.
+func (c *Client) send(ctx context.Context, baseURL, method, path string, data []byte, gzipped bool, o *callOptions) (*http.Response, error) {
+	url := fmt.Sprintf("%s%s", strings.TrimRight(baseURL, "/"), path)
+
+	var bodyReader io.Reader
+	if data != nil {
+		bodyReader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	respBytes, err := io.ReadAll(resp.Body)
+	req.Header.Set("Content-Type", "application/json")
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	apiKey := c.config.APIKey
+	if o.apiKey != nil {
+		apiKey = *o.apiKey
+	}
+	req.Header.Set(c.config.AuthHeader, authValue(c.config.AuthScheme, apiKey))
+	if o.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", o.idempotencyKey)
+	}
+	setContextHeaders(ctx, req, c.config.ContextHeaders)
+
+	atomic.AddInt64(&c.stats.requests, 1)
+	atomic.AddInt64(&c.stats.bytesSent, int64(len(data)))
+	resp, err := c.http.Do(req)
 	if err != nil {
+		if !hedgeLost(ctx) {
+			atomic.AddInt64(&c.stats.errors, 1)
+		}
 		return nil, err
 	}
+	resp.Body = countingReader{ReadCloser: resp.Body, n: &c.stats.bytesReceived}
+	o.recordResponse(resp)
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("api error %d: %s", resp.StatusCode, string(respBytes))
+		atomic.AddInt64(&c.stats.errors, 1)
+		defer resp.Body.Close()
+		respBytes, err := c.readBody(resp)
+		if err != nil {
+			return nil, err
+		}
+		return nil, httpError(resp.StatusCode, respBytes)
 	}
 
+	return resp, nil
+}
+
+// readBody buffers the response body. The limit is enforced while reading,
+// so it also holds for chunked responses that carry no Content-Length. The
+// read is bound to the request's context: a server that stalls mid-body
+// fails with the context's error once its deadline passes.
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	limit := c.config.MaxResponseBytes
+	if limit <= 0 {
+		respBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("barq: reading response: %w", err)
+		}
+		return respBytes, nil
+	}
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%w: limit is %d bytes, Content-Length is %d", ErrResponseTooLarge, limit, resp.ContentLength)
+	}
+
+	respBytes, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("barq: reading response: %w", err)
+	}
+	if int64(len(respBytes)) > limit {
+		return nil, fmt.Errorf("%w: limit is %d bytes", ErrResponseTooLarge, limit)
+	}
 	return respBytes, nil
 }
 
@@ -78,6 +416,23 @@ type CreateCollectionRequest struct {
 	Metric     string      `json:"metric"`
 	Index      interface{} `json:"index,omitempty"`
 	TextFields []TextField `json:"text_fields,omitempty"`
+	// PayloadFields declares typed payload fields, so filters on numeric
+	// and keyword fields can use an index.
+	PayloadFields []PayloadField `json:"payload_fields,omitempty"`
+	// Vectors declares additional named vectors stored alongside the
+	// default one.
+	Vectors []NamedVector `json:"vectors,omitempty"`
+	// IfNotExists treats an existing collection of the same name as
+	// success: a 409 is answered by describing the collection instead. It
+	// also makes the create safe to retry after network errors, since a
+	// replay of a create that did reach the server just finds it.
+	IfNotExists bool `json:"-"`
+}
+
+// NamedVector declares a named vector in a multi-vector collection.
+type NamedVector struct {
+	Name      string `json:"name"`
+	Dimension int    `json:"dimension"`
 }
 
 type TextField struct {
@@ -86,129 +441,1357 @@ type TextField struct {
 	Required bool   `json:"required"`
 }
 
-func (c *Client) CreateCollection(ctx context.Context, req CreateCollectionRequest) error {
-	_, err := c.request(ctx, "POST", "/collections", req)
+func (c *Client) CreateCollection(ctx context.Context, req CreateCollectionRequest, opts ...CallOption) error {
+	_, err := c.CreateCollectionWithInfo(ctx, req, opts...)
 	return err
 }
 
+// CreateCollectionFromSample creates a collection whose dimension is taken
+// from a sample embedding, for bootstrapping from data whose dimension is
+// not known offhand.
+func (c *Client) CreateCollectionFromSample(ctx context.Context, name string, sample []float32, metric Metric, opts ...CallOption) error {
+	if len(sample) == 0 {
+		return fmt.Errorf("barq: cannot infer the dimension of collection %q from an empty sample vector", name)
+	}
+	return c.CreateCollection(ctx, CreateCollectionRequest{
+		Name:      name,
+		Dimension: len(sample),
+		Metric:    string(metric),
+	}, opts...)
+}
+
+// CreateCollectionWithInfo creates a collection and returns its settings as
+// the server applied them. Servers that answer with an empty body get the
+// settings echoed from req instead.
+func (c *Client) CreateCollectionWithInfo(ctx context.Context, req CreateCollectionRequest, opts ...CallOption) (*CollectionInfo, error) {
+	if err := c.checkCollectionName(req.Name); err != nil {
+		return nil, err
+	}
+	for _, f := range req.PayloadFields {
+		if !payloadFieldTypes[f.Type] {
+			return nil, fmt.Errorf("barq: payload field %q has unknown type %q; want int, float, keyword or bool", f.Name, f.Type)
+		}
+	}
+	if req.IfNotExists {
+		opts = append(opts[:len(opts):len(opts)], idempotent())
+	}
+	respBytes, err := c.request(ctx, "POST", "/collections", req, opts...)
+	if req.IfNotExists && errors.Is(err, ErrConflict) {
+		return c.DescribeCollection(ctx, req.Name, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info := &CollectionInfo{
+		Name:          req.Name,
+		Dimension:     req.Dimension,
+		Metric:        Metric(req.Metric),
+		Index:         req.Index,
+		TextFields:    req.TextFields,
+		PayloadFields: req.PayloadFields,
+		Vectors:       req.Vectors,
+	}
+	if len(bytes.TrimSpace(respBytes)) > 0 {
+		var applied CollectionInfo
+		if err := c.decode(respBytes, &applied); err != nil {
+			return nil, err
+		}
+		if applied.Name != "" {
+			info = &applied
+		}
+	}
+	c.meta.set(info)
+	return info, nil
+}
+
+type CollectionInfo struct {
+	Name          string         `json:"name"`
+	Dimension     int            `json:"dimension"`
+	Metric        Metric         `json:"metric"`
+	Index         interface{}    `json:"index,omitempty"`
+	TextFields    []TextField    `json:"text_fields,omitempty"`
+	PayloadFields []PayloadField `json:"payload_fields,omitempty"`
+	Vectors       []NamedVector  `json:"vectors,omitempty"`
+	// ReadOnly is set while the server rejects writes to the collection;
+	// see SetCollectionReadOnly.
+	ReadOnly bool `json:"read_only,omitempty"`
+}
+
+// PayloadField describes a non-text payload field in a collection schema.
+// Type is PayloadInt, PayloadFloat, PayloadKeyword or PayloadBool.
+type PayloadField struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Indexed bool   `json:"indexed"`
+}
+
+// Payload field types accepted by CreateCollectionRequest.PayloadFields.
+const (
+	PayloadInt     = "int"
+	PayloadFloat   = "float"
+	PayloadKeyword = "keyword"
+	PayloadBool    = "bool"
+)
+
+var payloadFieldTypes = map[string]bool{
+	PayloadInt:     true,
+	PayloadFloat:   true,
+	PayloadKeyword: true,
+	PayloadBool:    true,
+}
+
+func (c *Client) DescribeCollection(ctx context.Context, name string, opts ...CallOption) (*CollectionInfo, error) {
+	if err := c.checkCollectionName(name); err != nil {
+		return nil, err
+	}
+	respBytes, err := c.request(ctx, "GET", collectionPath(name), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var info CollectionInfo
+	if err := c.decode(respBytes, &info); err != nil {
+		return nil, err
+	}
+	if info.Name == "" {
+		info.Name = name
+	}
+	c.meta.set(&info)
+	return &info, nil
+}
+
+// ListCollectionsOpts selects a page of ListCollections. Leave Cursor empty
+// on the first call. Zero Limit means the server's default page size.
+type ListCollectionsOpts struct {
+	Prefix string
+	Limit  int
+	Cursor string
+}
+
+// CollectionPage is one page of ListCollections.
+type CollectionPage struct {
+	Collections []CollectionInfo `json:"collections"`
+	// NextCursor continues the listing; it is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListCollections lists collections a page at a time, in the server's
+// order. Prefix is sent to the server and, for servers that ignore it, also
+// applied to the returned page, which may then hold fewer than Limit
+// collections.
+func (c *Client) ListCollections(ctx context.Context, o ListCollectionsOpts, opts ...CallOption) (*CollectionPage, error) {
+	query := url.Values{}
+	if o.Prefix != "" {
+		query.Set("prefix", o.Prefix)
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+	path := "/collections"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	respBytes, err := c.request(ctx, "GET", path, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var page CollectionPage
+	if err := c.decode(respBytes, &page); err != nil {
+		return nil, err
+	}
+	matched := make([]CollectionInfo, 0, len(page.Collections))
+	for _, info := range page.Collections {
+		if strings.HasPrefix(info.Name, o.Prefix) {
+			matched = append(matched, info)
+		}
+	}
+	page.Collections = matched
+	return &page, nil
+}
+
+// collectionMetric returns the metric of collection if this client has it
+// cached from CreateCollection or DescribeCollection, and "" otherwise, in
+// which case Similarity falls back to the raw score. It never sends a
+// request: searches must not cost a hidden describe, which servers without
+// the endpoint would fail every time.
+func (c *Client) collectionMetric(collection string) Metric {
+	if info, ok := c.meta.get(collection); ok {
+		return info.Metric
+	}
+	return ""
+}
+
 type InsertRequest struct {
+	ID      interface{}     `json:"id"`
+	Vector  []float32       `json:"vector,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// PayloadContentType marks Payload as opaque bytes of that media type,
+	// such as "application/x-protobuf", which the server stores and returns
+	// without parsing; the bytes travel base64 encoded. Empty, or
+	// "application/json", means Payload is JSON.
+	PayloadContentType string `json:"payload_content_type,omitempty"`
+	// Vectors holds the document's named vectors, keyed by the names
+	// declared in the collection schema. Each is checked against its
+	// declared dimension before the request is sent.
+	Vectors map[string][]float32 `json:"vectors,omitempty"`
+	// WaitForIndex makes the server respond only after the document is
+	// indexed and visible to searches. This adds the indexing time to the
+	// insert latency, so leave it off for bulk loads that can tolerate a
+	// short delay before documents become searchable.
+	WaitForIndex bool `json:"wait_for_index,omitempty"`
+	// IfVersion makes the write conditional: the server applies it only if
+	// the stored document's Version equals *IfVersion, and otherwise fails
+	// with an error matching ErrConflict. Use it for read-modify-write
+	// with GetDocument. Requires server support.
+	IfVersion *int64 `json:"if_version,omitempty"`
+	// Upsert replaces an existing document with the same ID instead of
+	// failing.
+	Upsert bool `json:"upsert,omitempty"`
+	// TTL makes the document expire that long after the server accepts
+	// it. It is sent as whole seconds, rounded up; zero means never.
+	TTL time.Duration `json:"-"`
+}
+
+func (c *Client) Insert(ctx context.Context, collection string, req InsertRequest, opts ...CallOption) error {
+	_, err := c.insert(ctx, collection, req, opts...)
+	return err
+}
+
+// InsertWithID inserts a document and returns its ID. Leave req.ID nil to
+// have the server assign one; the ID is then read from the response. When
+// the server echoes no ID, req.ID is returned, or an error if it was nil.
+func (c *Client) InsertWithID(ctx context.Context, collection string, req InsertRequest, opts ...CallOption) (interface{}, error) {
+	ack, err := c.InsertAck(ctx, collection, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ack.ID, nil
+}
+
+// InsertAck is the server's acknowledgement of an insert.
+type InsertAck struct {
+	ID interface{}
+	// Offset is the server's log position for the write, increasing with
+	// every insert into the collection, for checkpointing pipelines. Zero
+	// means the server does not report offsets, not that it is the first.
+	Offset int64
+}
+
+// InsertAck inserts a document like InsertWithID and returns its ID together
+// with the offset the server assigned to the write. The offset is read from
+// an "offset" or "lsn" member of the response.
+func (c *Client) InsertAck(ctx context.Context, collection string, req InsertRequest, opts ...CallOption) (*InsertAck, error) {
+	respBytes, err := c.insert(ctx, collection, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ack := &InsertAck{ID: req.ID}
+	if len(bytes.TrimSpace(respBytes)) > 0 {
+		var resp struct {
+			ID     interface{} `json:"id"`
+			Offset int64       `json:"offset"`
+			LSN    int64       `json:"lsn"`
+		}
+		if err := c.decode(respBytes, &resp); err != nil {
+			return nil, err
+		}
+		if resp.ID != nil {
+			ack.ID = plainID(resp.ID)
+		}
+		ack.Offset = resp.Offset
+		if ack.Offset == 0 {
+			ack.Offset = resp.LSN
+		}
+	}
+	if ack.ID == nil {
+		return nil, errors.New("barq: server did not return the assigned document id")
+	}
+	return ack, nil
+}
+
+func (c *Client) insert(ctx context.Context, collection string, req InsertRequest, opts ...CallOption) ([]byte, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return nil, err
+	}
+	if err := c.checkWritable(collection); err != nil {
+		return nil, err
+	}
+	if err := checkVectors(req.Vector, req.Vectors); err != nil {
+		return nil, err
+	}
+	if err := c.validateVectors(ctx, collection, req.Vectors); err != nil {
+		return nil, err
+	}
+	path := collectionPath(collection, "documents")
+	respBytes, err := c.request(ctx, "POST", path, c.insertBody(req), opts...)
+	if err == nil {
+		atomic.AddInt64(&c.stats.documentsInserted, 1)
+	}
+	return respBytes, err
+}
+
+// InsertRaw inserts a document that is already in the wire shape, a JSON
+// object such as {"id": 1, "vector": [...], "payload": {...}}, forwarding it
+// without decoding it into an InsertRequest. It only checks that doc is an
+// object with a vector; the server validates the rest.
+func (c *Client) InsertRaw(ctx context.Context, collection string, doc json.RawMessage, opts ...CallOption) error {
+	if err := c.checkCollectionName(collection); err != nil {
+		return err
+	}
+	if err := c.checkWritable(collection); err != nil {
+		return err
+	}
+	trimmed := bytes.TrimSpace(doc)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return errors.New("barq: raw document must be a JSON object")
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return fmt.Errorf("barq: raw document: %w", err)
+	}
+	if !present(fields["vector"]) && !present(fields["vectors"]) {
+		return errors.New("barq: raw document has no vector")
+	}
+
+	_, err := c.request(ctx, "POST", collectionPath(collection, "documents"), json.RawMessage(trimmed), opts...)
+	if err == nil {
+		atomic.AddInt64(&c.stats.documentsInserted, 1)
+	}
+	return err
+}
+
+// present reports whether a decoded JSON member exists and is not null.
+func present(v json.RawMessage) bool {
+	return len(v) > 0 && string(v) != "null"
+}
+
+// DocumentPage is one page of ListDocuments.
+type DocumentPage struct {
+	Documents []Document `json:"documents"`
+	// NextCursor continues the listing; it is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ListDocuments lists a collection's documents in storage order, limit at a
+// time (the server's default when zero). Start with an empty cursor and pass
+// each page's NextCursor to get the next one.
+func (c *Client) ListDocuments(ctx context.Context, collection, cursor string, limit int, opts ...CallOption) (*DocumentPage, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return nil, err
+	}
+	respBytes, err := c.request(ctx, "GET", listDocumentsPath(collection, cursor, limit), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var page DocumentPage
+	if err := c.decode(respBytes, &page); err != nil {
+		return nil, err
+	}
+	for i := range page.Documents {
+		if err := c.checkPayloads(&page.Documents[i]); err != nil {
+			return nil, err
+		}
+	}
+	if page.Documents == nil {
+		page.Documents = []Document{}
+	}
+	return &page, nil
+}
+
+func listDocumentsPath(collection, cursor string, limit int) string {
+	query := url.Values{}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	path := collectionPath(collection, "documents")
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path
+}
+
+// DeleteExpired asks the server to remove documents whose TTL has passed
+// and returns how many it deleted. Servers that expire documents on their
+// own do not need it.
+func (c *Client) DeleteExpired(ctx context.Context, collection string, opts ...CallOption) (int, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return 0, err
+	}
+	if err := c.checkWritable(collection); err != nil {
+		return 0, err
+	}
+	path := collectionPath(collection, "delete_expired")
+	respBytes, err := c.request(ctx, "POST", path, nil, opts...)
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		Deleted int `json:"deleted"`
+	}
+	if err := c.decode(respBytes, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Deleted, nil
+}
+
+type Document struct {
 	ID      interface{}     `json:"id"`
 	Vector  []float32       `json:"vector"`
 	Payload json.RawMessage `json:"payload,omitempty"`
+	// Vectors holds the document's named vectors, as in
+	// InsertRequest.Vectors.
+	Vectors map[string][]float32 `json:"vectors,omitempty"`
+	// PayloadContentType is the InsertRequest.PayloadContentType the
+	// document was stored with. For a non-JSON type, Payload holds the
+	// stored bytes as they were inserted.
+	PayloadContentType string `json:"payload_content_type,omitempty"`
+	// Version changes on every write, for use with
+	// InsertRequest.IfVersion. It is zero on servers without versioning.
+	Version int64 `json:"version,omitempty"`
+	// DeletedAt is set on a soft-deleted document; see SoftDelete.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// GetDocument fetches a stored document. A missing document yields an error
+// matching ErrNotFound.
+func (c *Client) GetDocument(ctx context.Context, collection string, id interface{}, opts ...CallOption) (*Document, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return nil, err
+	}
+	path := collectionPath(collection, "documents", url.PathEscape(idKey(id)))
+	respBytes, err := c.request(ctx, "GET", path, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Document *Document `json:"document"`
+	}
+	if err := c.decode(respBytes, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Document == nil {
+		return nil, fmt.Errorf("document %v in %q: %w", id, collection, ErrNotFound)
+	}
+	if err := c.checkPayloads(resp.Document); err != nil {
+		return nil, err
+	}
+	return resp.Document, nil
 }
 
-func (c *Client) Insert(ctx context.Context, collection string, req InsertRequest) error {
-	path := fmt.Sprintf("/collections/%s/documents", collection)
-	_, err := c.request(ctx, "POST", path, req)
+func (c *Client) DeleteDocument(ctx context.Context, collection string, id interface{}, opts ...CallOption) error {
+	if err := c.checkCollectionName(collection); err != nil {
+		return err
+	}
+	if err := c.checkWritable(collection); err != nil {
+		return err
+	}
+	path := collectionPath(collection, "documents", url.PathEscape(idKey(id)))
+	if newCallOptions(opts).softDelete {
+		if err := c.checkSoftDelete(ctx); err != nil {
+			return err
+		}
+		path += "?soft=true"
+	}
+	_, err := c.request(ctx, "DELETE", path, nil, opts...)
 	return err
 }
 
+// plainID unwraps the server's tagged document ID form, {"U64": 7} or
+// {"Str": "a"}, into the bare value.
+func plainID(id interface{}) interface{} {
+	if m, ok := id.(map[string]interface{}); ok && len(m) == 1 {
+		if v, ok := m["U64"]; ok {
+			return v
+		}
+		if v, ok := m["Str"]; ok {
+			return v
+		}
+	}
+	return id
+}
+
+// idKey renders a document ID in a canonical string form so IDs sent as Go
+// values compare equal to IDs decoded from server JSON, including the
+// tagged {"U64": n} / {"Str": s} encoding.
+func idKey(id interface{}) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case json.Number:
+		return v.String()
+	case map[string]interface{}:
+		if len(v) == 1 {
+			for _, inner := range v {
+				return idKey(inner)
+			}
+		}
+	}
+	return fmt.Sprintf("%v", id)
+}
+
+// PayloadUpdate changes the payload of an existing document without
+// re-sending its vector. With Merge set, top-level keys in Payload are
+// merged into the stored payload (keys set to null are removed); otherwise
+// the stored payload is replaced wholesale.
+type PayloadUpdate struct {
+	ID      interface{}     `json:"id"`
+	Payload json.RawMessage `json:"payload"`
+	Merge   bool            `json:"merge"`
+}
+
+type ItemStatus string
+
+const (
+	ItemOK       ItemStatus = "ok"
+	ItemNotFound ItemStatus = "not_found"
+	ItemFailed   ItemStatus = "error"
+	// ItemSkipped marks a document InsertConcurrent did not send because
+	// ConcurrencyOptions.Dedup found it a duplicate.
+	ItemSkipped ItemStatus = "skipped"
+)
+
+type ItemResult struct {
+	ID     interface{} `json:"id"`
+	Status ItemStatus  `json:"status"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// InsertReport summarizes a batch write. Items are in request order.
+type InsertReport struct {
+	Items     []ItemResult
+	Succeeded int
+	NotFound  int
+	Failed    int
+	// Skipped counts the duplicates ConcurrencyOptions.Dedup left out.
+	Skipped int
+}
+
+func newInsertReport(items []ItemResult) *InsertReport {
+	report := &InsertReport{Items: items}
+	for _, item := range items {
+		switch item.Status {
+		case ItemOK:
+			report.Succeeded++
+		case ItemNotFound:
+			report.NotFound++
+		case ItemSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	return report
+}
+
+func (c *Client) BatchUpdatePayload(ctx context.Context, collection string, updates []PayloadUpdate, opts ...CallOption) (*InsertReport, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return nil, err
+	}
+	if err := c.checkWritable(collection); err != nil {
+		return nil, err
+	}
+	if len(updates) == 0 {
+		return newInsertReport(nil), nil
+	}
+
+	path := collectionPath(collection, "batch_update_payload")
+	respBytes, err := c.request(ctx, "POST", path, map[string]interface{}{"updates": updates}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []ItemResult `json:"results"`
+	}
+	if err := c.decode(respBytes, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(updates) {
+		return nil, fmt.Errorf("batch update: expected %d results, got %d", len(updates), len(resp.Results))
+	}
+	return newInsertReport(resp.Results), nil
+}
+
+// MaxFuzziness is the largest SearchRequest.Fuzziness, in edits per term.
+const MaxFuzziness = 2
+
 type SearchRequest struct {
-	Vector []float32   `json:"vector,omitempty"`
-	Query  string      `json:"query,omitempty"`
-	TopK   int         `json:"top_k"`
-	Filter interface{} `json:"filter,omitempty"`
+	Vector []float32 `json:"vector,omitempty"`
+	// VectorField selects the named vector Vector is matched against; empty
+	// means the collection's default vector.
+	VectorField string `json:"vector_field,omitempty"`
+	Query       string `json:"query,omitempty"`
+	// TextField selects the text field Query is matched against in text
+	// and hybrid searches; empty means the server's default field.
+	TextField string `json:"text_field,omitempty"`
+	// MinShouldMatch is how many query terms a document must contain in
+	// text and hybrid searches; zero leaves it to the server. It is not
+	// sent for pure vector searches.
+	MinShouldMatch int `json:"min_should_match,omitempty"`
+	// Fuzziness lets query terms in text and hybrid searches match terms up
+	// to that many edits away, so misspellings still match. It must be 0
+	// to MaxFuzziness; zero means exact terms. It is not sent for pure
+	// vector searches.
+	Fuzziness int `json:"fuzziness,omitempty"`
+	// Highlight asks text and hybrid searches to return matched snippets in
+	// SearchResult.Highlights. It is not sent for pure vector searches.
+	Highlight bool        `json:"highlight,omitempty"`
+	TopK      int         `json:"top_k"`
+	Filter    interface{} `json:"filter,omitempty"`
+	// FilterMode chooses pre- or post-filtering; empty means the server's
+	// choice. It is not sent without a Filter.
+	FilterMode FilterMode `json:"filter_mode,omitempty"`
+	// IncludeVector asks the server to return each result's vector in
+	// SearchResult.Vector. Loading vectors makes the search slower and the
+	// response larger.
+	IncludeVector bool `json:"include_vector,omitempty"`
+	// ScoreOnly asks the server to skip loading stored documents and return
+	// only IDs and scores. Results never carry vectors or highlights, even
+	// from servers that ignore the flag. It cannot be combined with
+	// IncludeVector or Highlight.
+	ScoreOnly bool `json:"score_only,omitempty"`
+	// Facets lists payload fields to count values of across the matching
+	// documents, returned in SearchResponse.Facets. It is not sent when
+	// empty.
+	Facets []string `json:"facets,omitempty"`
+	// PageToken continues a previous search from its NextToken.
+	PageToken string `json:"page_token,omitempty"`
+	// After asks for only the results ranking after this one, for keyset
+	// paging; see SearchIterator.Cursor. Servers without support ignore
+	// it, so prefer SearchIter, which also filters on the client.
+	After *SearchCursor `json:"search_after,omitempty"`
+	// TimeBudget asks the server to stop searching once it elapses and
+	// return the best results found so far, marking the response Partial.
+	// It is sent as whole milliseconds, rounded up; zero means no budget.
+	TimeBudget time.Duration `json:"-"`
+	// NormalizeScores fills SearchResult.NormScore, scaling the results'
+	// Similarity into [0, 1] relative to each other. It is applied by the
+	// client and not sent.
+	NormalizeScores bool `json:"-"`
+}
+
+// SearchCursor is a position in a ranking: the score and ID of the last
+// result seen. It is plain JSON, so a UI can hand it to the browser and get
+// it back with the next page request.
+type SearchCursor struct {
+	Score float32     `json:"score"`
+	ID    interface{} `json:"id"`
 }
 
 type SearchResponse struct {
 	Results []SearchResult `json:"results"`
+	// NextToken is set by servers that support continuation tokens when
+	// more results are available.
+	NextToken string `json:"next_token,omitempty"`
+	// Total is the number of documents matching the search, on servers
+	// that report it; zero otherwise.
+	Total int `json:"total,omitempty"`
+	// Partial is set when the search stopped at its TimeBudget, so better
+	// results may exist.
+	Partial bool `json:"partial,omitempty"`
+	// Facets maps each field of SearchRequest.Facets to its values and how
+	// many matching documents have each. It is nil from servers without
+	// facet support.
+	Facets map[string]map[string]int64 `json:"facets,omitempty"`
+}
+
+// searchWire is the request body of a search, with the fields that need
+// converting.
+type searchWire struct {
+	SearchRequest
+	TimeBudgetMS int64 `json:"time_budget_ms,omitempty"`
+}
+
+func searchBody(req SearchRequest) searchWire {
+	wire := searchWire{SearchRequest: req}
+	if req.TimeBudget > 0 {
+		wire.TimeBudgetMS = int64((req.TimeBudget + time.Millisecond - 1) / time.Millisecond)
+	}
+	return wire
 }
 
 type SearchResult struct {
 	ID    interface{} `json:"id"`
 	Score float32     `json:"score"`
+	// Vector is the stored vector when IncludeVector was set.
+	Vector []float32 `json:"vector,omitempty"`
+	// Payload is the document's payload when the server returns it with
+	// results, as GrpcClient.Search does from payload_json.
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Highlights maps field names to matched snippets when Highlight was
+	// requested and the server supports it; otherwise it is nil.
+	Highlights map[string][]string `json:"highlights,omitempty"`
+	// Similarity is Score converted so that higher is always better,
+	// whatever the collection's metric. See Metric.Similarity.
+	Similarity float32 `json:"-"`
+	// NormScore is Similarity min-max scaled over the returned results,
+	// 1 for the best and 0 for the worst, when NormalizeScores was set.
+	NormScore float32 `json:"-"`
+}
+
+// scoreOnly drops everything but the ID and score.
+func (r *SearchResult) scoreOnly() {
+	r.Vector = nil
+	r.Payload = nil
+	r.Highlights = nil
+}
+
+func (c *Client) Search(ctx context.Context, collection string, req SearchRequest, opts ...CallOption) ([]SearchResult, error) {
+	resp, err := c.searchPage(ctx, collection, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
+
+// SearchWithMeta is Search returning the whole response, so callers can see
+// Partial, Total and NextToken alongside the results.
+func (c *Client) SearchWithMeta(ctx context.Context, collection string, req SearchRequest, opts ...CallOption) (*SearchResponse, error) {
+	return c.searchPage(ctx, collection, req, opts...)
+}
+
+// searchPage runs a search and returns the whole response, so callers can
+// see NextToken.
+func (c *Client) searchPage(ctx context.Context, collection string, req SearchRequest, opts ...CallOption) (*SearchResponse, error) {
+	req, err := c.prepareSearch(ctx, collection, req)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts[:len(opts):len(opts)], readCall())
+	respBytes, err := c.request(ctx, "POST", searchPath(collection, req), searchBody(req), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp SearchResponse
+	if err := c.decode(respBytes, &resp); err != nil {
+		return nil, err
+	}
+	if err := c.checkResultPayloads(resp.Results); err != nil {
+		return nil, err
+	}
+	if resp.Results == nil {
+		resp.Results = []SearchResult{}
+	}
+	if req.ScoreOnly {
+		for i := range resp.Results {
+			resp.Results[i].scoreOnly()
+		}
+	}
+	setSimilarity(resp.Results, c.collectionMetric(collection))
+	if req.NormalizeScores {
+		normalizeScores(resp.Results)
+	}
+	atomic.AddInt64(&c.stats.searchResults, int64(len(resp.Results)))
+	return &resp, nil
+}
+
+// topK resolves a requested TopK against Config.DefaultTopK, which only
+// fills in a zero.
+func (c *Client) topK(k int) (int, error) {
+	if k == 0 {
+		k = c.config.DefaultTopK
+	}
+	if k <= 0 {
+		if k == 0 {
+			return 0, errors.New("barq: search TopK is zero and Config.DefaultTopK is not set")
+		}
+		return 0, fmt.Errorf("barq: search TopK must be positive, got %d", k)
+	}
+	return k, nil
+}
+
+// prepareSearch validates req and drops fields that do not apply to the
+// endpoint it is routed to.
+func (c *Client) prepareSearch(ctx context.Context, collection string, req SearchRequest) (SearchRequest, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return req, err
+	}
+	if req.MinShouldMatch < 0 {
+		return req, fmt.Errorf("barq: MinShouldMatch must not be negative, got %d", req.MinShouldMatch)
+	}
+	if req.Fuzziness < 0 || req.Fuzziness > MaxFuzziness {
+		return req, fmt.Errorf("barq: Fuzziness must be 0 to %d, got %d", MaxFuzziness, req.Fuzziness)
+	}
+	topK, err := c.topK(req.TopK)
+	if err != nil {
+		return req, err
+	}
+	req.TopK = topK
+	if req.Query == "" {
+		req.MinShouldMatch = 0
+		req.Fuzziness = 0
+		req.Highlight = false
+	}
+	if req.Filter == nil {
+		req.FilterMode = ""
+	}
+	if req.FilterMode != "" && req.FilterMode != FilterPre && req.FilterMode != FilterPost {
+		return req, fmt.Errorf("barq: unknown FilterMode %q; want %q or %q", req.FilterMode, FilterPre, FilterPost)
+	}
+	if req.ScoreOnly && (req.IncludeVector || req.Highlight) {
+		return req, errors.New("barq: ScoreOnly cannot be combined with IncludeVector or Highlight")
+	}
+	for _, field := range req.Facets {
+		if field == "" {
+			return req, errors.New("barq: facet field names must not be empty")
+		}
+	}
+	if err := c.validateTextField(collection, req.TextField); err != nil {
+		return req, err
+	}
+	if err := c.validateFilter(ctx, collection, req.Filter); err != nil {
+		return req, err
+	}
+	if err := checkVector(req.Vector); err != nil {
+		return req, err
+	}
+	vector, err := c.searchVector(collection, req.VectorField, req.Vector)
+	if err != nil {
+		return req, err
+	}
+	req.Vector = vector
+	return req, nil
 }
 
-func (c *Client) Search(ctx context.Context, collection string, req SearchRequest) ([]SearchResult, error) {
-	path := fmt.Sprintf("/collections/%s/search", collection)
+func searchPath(collection string, req SearchRequest) string {
+	path := collectionPath(collection, "search")
 	if req.Vector != nil && req.Query != "" {
 		path += "/hybrid"
 	} else if req.Query != "" {
 		path += "/text"
 	}
+	return path
+}
+
+// SearchByID finds documents similar to the stored document id by fetching
+// its vector and searching with it. With excludeSelf the source document is
+// dropped from the results and one extra hit is requested to compensate.
+// A missing source document yields an error matching ErrNotFound.
+func (c *Client) SearchByID(ctx context.Context, collection string, id interface{}, topK int, excludeSelf bool, opts ...CallOption) ([]SearchResult, error) {
+	return c.searchByID(ctx, collection, id, topK, excludeSelf, nil, opts...)
+}
 
-	respBytes, err := c.request(ctx, "POST", path, req)
+func (c *Client) searchByID(ctx context.Context, collection string, id interface{}, topK int, excludeSelf bool, filter interface{}, opts ...CallOption) ([]SearchResult, error) {
+	topK, err := c.topK(topK)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.GetDocument(ctx, collection, id, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp SearchResponse
-	if err := json.Unmarshal(respBytes, &resp); err != nil {
+	k := topK
+	if excludeSelf {
+		k++
+	}
+	results, err := c.Search(ctx, collection, SearchRequest{Vector: doc.Vector, TopK: k, Filter: filter}, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return resp.Results, nil
+	if !excludeSelf {
+		return results, nil
+	}
+
+	self := idKey(id)
+	filtered := results[:0]
+	for _, r := range results {
+		if idKey(r.ID) != self {
+			filtered = append(filtered, r)
+		}
+	}
+	if len(filtered) > topK {
+		filtered = filtered[:topK]
+	}
+	return filtered, nil
+}
+
+// batchSearchQuery is one query of the batch search endpoint. TopK is also
+// enforced client-side, for servers that only honor the shared top_k.
+type batchSearchQuery struct {
+	Vector      []float32   `json:"vector"`
+	VectorField string      `json:"vector_field,omitempty"`
+	Filter      interface{} `json:"filter,omitempty"`
+	FilterMode  FilterMode  `json:"filter_mode,omitempty"`
+	TopK        int         `json:"top_k"`
+	normalize   bool
+}
+
+// SearchMany runs one vector search per row of vectors through the batch
+// search endpoint. The i-th result set answers vectors[i].
+func (c *Client) SearchMany(ctx context.Context, collection string, vectors [][]float32, topK int, opts ...CallOption) ([][]SearchResult, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return nil, err
+	}
+	topK, err := c.topK(topK)
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]batchSearchQuery, len(vectors))
+	for i, v := range vectors {
+		if err := checkVector(v); err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+		v, err := c.searchVector(collection, "", v)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = batchSearchQuery{Vector: v, TopK: topK}
+	}
+	return c.batchSearch(ctx, collection, queries, opts...)
+}
+
+// BatchSearch runs several vector searches in one request, each with its
+// own TopK, VectorField, Filter and FilterMode. The i-th result set answers
+// reqs[i]. Every request needs a Vector and a TopK, or Config.DefaultTopK;
+// text and hybrid searches are not supported by the batch endpoint.
+func (c *Client) BatchSearch(ctx context.Context, collection string, reqs []SearchRequest, opts ...CallOption) ([][]SearchResult, error) {
+	queries := make([]batchSearchQuery, len(reqs))
+	for i, req := range reqs {
+		if req.Query != "" || len(req.Vector) == 0 {
+			return nil, fmt.Errorf("barq: batch search request %d: only vector searches can be batched", i)
+		}
+		req, err := c.prepareSearch(ctx, collection, req)
+		if err != nil {
+			return nil, fmt.Errorf("barq: batch search request %d: %w", i, err)
+		}
+		queries[i] = batchSearchQuery{
+			Vector:      req.Vector,
+			VectorField: req.VectorField,
+			Filter:      req.Filter,
+			FilterMode:  req.FilterMode,
+			TopK:        req.TopK,
+			normalize:   req.NormalizeScores,
+		}
+	}
+	return c.batchSearch(ctx, collection, queries, opts...)
+}
+
+func (c *Client) batchSearch(ctx context.Context, collection string, queries []batchSearchQuery, opts ...CallOption) ([][]SearchResult, error) {
+	if len(queries) == 0 {
+		return [][]SearchResult{}, nil
+	}
+	topK := 0
+	for _, q := range queries {
+		if q.TopK > topK {
+			topK = q.TopK
+		}
+	}
+
+	path := collectionPath(collection, "batch_search")
+	opts = append(opts[:len(opts):len(opts)], readCall())
+	respBytes, err := c.request(ctx, "POST", path, map[string]interface{}{
+		"queries": queries,
+		"top_k":   topK,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Hits []SearchResult `json:"hits"`
+		} `json:"results"`
+	}
+	if err := c.decode(respBytes, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(queries) {
+		return nil, fmt.Errorf("batch search: expected %d result sets, got %d", len(queries), len(resp.Results))
+	}
+	for _, r := range resp.Results {
+		if err := c.checkResultPayloads(r.Hits); err != nil {
+			return nil, err
+		}
+	}
+
+	metric := c.collectionMetric(collection)
+	out := make([][]SearchResult, len(resp.Results))
+	for i, r := range resp.Results {
+		if r.Hits == nil {
+			r.Hits = []SearchResult{}
+		}
+		if k := queries[i].TopK; k > 0 && len(r.Hits) > k {
+			r.Hits = r.Hits[:k]
+		}
+		setSimilarity(r.Hits, metric)
+		if queries[i].normalize {
+			normalizeScores(r.Hits)
+		}
+		atomic.AddInt64(&c.stats.searchResults, int64(len(r.Hits)))
+		out[i] = r.Hits
+	}
+	return out, nil
 }
 
 // gRPC Client
 
 type GrpcClient struct {
-	conn   *grpc.ClientConn
-	client pb.BarqClient
+	conn        *grpc.ClientConn
+	client      pb.BarqClient
+	metrics     metricCache
+	maxMsgSize  int
+	namePattern *regexp.Regexp
+	// binaryPayload caches whether the server reads the bytes payload
+	// field of InsertDocumentRequest.
+	binaryPayload featureProbe
+
+	// closed is cancelled by Close to stop state watchers.
+	closed context.Context
+	stop   context.CancelFunc
 }
 
-func NewGrpcClient(target string) (*GrpcClient, error) {
-	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewGrpcClient(target string, opts ...GrpcOption) (*GrpcClient, error) {
+	o := grpcOptions{timeout: DefaultTimeout, authHeader: DefaultAuthHeader, maxMsgSize: DefaultGrpcMaxMsgSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, o.buildDialOptions()...)
+	conn, err := grpc.Dial(target, dialOpts...)
 	if err != nil {
 		return nil, err
 	}
 	client := pb.NewBarqClient(conn)
-	return &GrpcClient{conn: conn, client: client}, nil
+	closed, stop := context.WithCancel(context.Background())
+	return &GrpcClient{conn: conn, client: client, maxMsgSize: o.maxMsgSize, namePattern: o.namePattern, closed: closed, stop: stop}, nil
 }
 
 func (c *GrpcClient) Close() error {
+	c.stop()
 	return c.conn.Close()
 }
 
+// OnStateChange calls cb from a background goroutine each time the channel's
+// connectivity state changes, until Close. When the channel falls back to
+// Idle (for example after the server drops the connection) the watcher asks
+// it to reconnect right away rather than on the next call. cb must not block
+// for long, as later transitions are observed only after it returns.
+func (c *GrpcClient) OnStateChange(cb func(connectivity.State)) {
+	go func() {
+		state := c.conn.GetState()
+		for c.conn.WaitForStateChange(c.closed, state) {
+			state = c.conn.GetState()
+			cb(state)
+			if state == connectivity.Idle {
+				c.conn.Connect()
+			}
+		}
+	}()
+}
+
 func (c *GrpcClient) Health(ctx context.Context) (bool, error) {
 	resp, err := c.client.Health(ctx, &pb.HealthRequest{})
 	if err != nil {
-		return false, err
+		return false, grpcError(err)
 	}
 	return resp.Ok, nil
 }
 
+// HealthStatus is the detailed health reported by GrpcClient.HealthDetailed.
+type HealthStatus struct {
+	OK      bool
+	Version string
+	// Components maps subsystem names such as "index" and "storage" to
+	// their status. Servers that report no components leave it empty.
+	Components map[string]ComponentHealth
+	// Features lists optional capabilities the server advertises.
+	Features []string
+}
+
+type ComponentHealth struct {
+	OK     bool
+	Detail string
+}
+
+// HealthDetailed is Health with the server version and per-component status,
+// for readiness checks that care which subsystem is failing.
+func (c *GrpcClient) HealthDetailed(ctx context.Context) (*HealthStatus, error) {
+	resp, err := c.client.Health(ctx, &pb.HealthRequest{})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	status := &HealthStatus{
+		OK:         resp.Ok,
+		Version:    resp.Version,
+		Components: make(map[string]ComponentHealth, len(resp.Components)),
+		Features:   resp.Features,
+	}
+	for _, comp := range resp.Components {
+		status.Components[comp.Name] = ComponentHealth{OK: comp.Ok, Detail: comp.Detail}
+	}
+	return status, nil
+}
+
 func (c *GrpcClient) CreateCollection(ctx context.Context, name string, dimension int, metric string) error {
+	if err := checkCollectionName(c.namePattern, name); err != nil {
+		return err
+	}
 	_, err := c.client.CreateCollection(ctx, &pb.CreateCollectionRequest{
 		Name:      name,
 		Dimension: uint32(dimension),
 		Metric:    metric,
 	})
-	return err
+	if err == nil {
+		c.metrics.set(name, Metric(metric))
+	}
+	return grpcError(err)
 }
 
-func (c *GrpcClient) InsertDocument(ctx context.Context, collection string, id interface{}, vector []float32, payload interface{}) error {
-	idStr := fmt.Sprintf("%v", id)
-	
-	payloadBytes, err := json.Marshal(payload)
+// InsertOption adjusts a single GrpcClient.InsertDocument call.
+type InsertOption func(*pb.InsertDocumentRequest)
+
+// WaitForIndex is the gRPC counterpart of InsertRequest.WaitForIndex.
+func WaitForIndex() InsertOption {
+	return func(req *pb.InsertDocumentRequest) { req.WaitForIndex = true }
+}
+
+func (c *GrpcClient) InsertDocument(ctx context.Context, collection string, id interface{}, vector []float32, payload interface{}, opts ...InsertOption) error {
+	if err := checkCollectionName(c.namePattern, collection); err != nil {
+		return err
+	}
+	req, err := insertDocumentRequest(collection, GrpcDocument{ID: id, Vector: vector, Payload: payload}, c.binaryPayloads(ctx), opts)
 	if err != nil {
 		return err
 	}
+	_, err = c.client.InsertDocument(ctx, req)
+	return grpcError(err)
+}
 
-	_, err = c.client.InsertDocument(ctx, &pb.InsertDocumentRequest{
-		Collection:  collection,
-		Id:          idStr,
-		Vector:      vector,
-		PayloadJson: string(payloadBytes),
+// GrpcDocument is one document of GrpcClient.InsertBatch.
+type GrpcDocument struct {
+	ID      interface{}
+	Vector  []float32
+	Payload interface{}
+}
+
+// binaryPayloads reports whether payloads can be sent in the bytes field,
+// asking the server once. Until a check succeeds, payloads go in the
+// payload_json string every server reads.
+func (c *GrpcClient) binaryPayloads(ctx context.Context) bool {
+	supported, _ := c.binaryPayload.check(func() (bool, error) {
+		status, err := c.HealthDetailed(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, f := range status.Features {
+			if f == "binary_payload" {
+				return true, nil
+			}
+		}
+		return false, nil
 	})
-	return err
+	return supported
+}
+
+func insertDocumentRequest(collection string, doc GrpcDocument, binary bool, opts []InsertOption) (*pb.InsertDocumentRequest, error) {
+	if err := checkVector(doc.Vector); err != nil {
+		return nil, err
+	}
+	payloadBytes, err := json.Marshal(doc.Payload)
+	if err != nil {
+		return nil, err
+	}
+	req := &pb.InsertDocumentRequest{
+		Collection: collection,
+		Id:         grpcID(doc.ID),
+		Vector:     doc.Vector,
+	}
+	if binary {
+		req.Payload = payloadBytes
+	} else {
+		req.PayloadJson = string(payloadBytes)
+	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req, nil
+}
+
+// InsertBatch inserts docs with the BatchInsert RPC and returns how many were
+// inserted. Batches larger than the client's maximum message size (see
+// WithGrpcMaxMsgSize) are split transparently into several calls; a single
+// document that does not fit on its own fails with ErrDocumentTooLarge
+// before anything is sent. If a call fails, the count covers the calls that
+// succeeded before it.
+func (c *GrpcClient) InsertBatch(ctx context.Context, collection string, docs []GrpcDocument, opts ...InsertOption) (int, error) {
+	if err := checkCollectionName(c.namePattern, collection); err != nil {
+		return 0, err
+	}
+	reqs := make([]*pb.InsertDocumentRequest, len(docs))
+	binary := c.binaryPayloads(ctx)
+	for i, doc := range docs {
+		req, err := insertDocumentRequest("", doc, binary, opts)
+		if err != nil {
+			return 0, fmt.Errorf("document %d: %w", i, err)
+		}
+		reqs[i] = req
+	}
+
+	chunks, err := chunkDocuments(collection, reqs, c.maxMsgSize)
+	if err != nil {
+		return 0, err
+	}
+	inserted := 0
+	for _, chunk := range chunks {
+		resp, err := c.client.BatchInsert(ctx, &pb.BatchInsertRequest{Collection: collection, Documents: chunk})
+		if err != nil {
+			return inserted, grpcError(err)
+		}
+		inserted += int(resp.Inserted)
+	}
+	return inserted, nil
+}
+
+// BatchDeleteStream deletes the documents whose IDs arrive on ids over one
+// client-streaming BatchDelete call, until ids is closed, and returns how
+// many the server removed. IDs are sent in the same string form as
+// InsertDocument uses. The server reports its count only when the stream
+// completes, so after a failure or cancellation the count is zero even
+// though some deletes may have been applied; deletes are idempotent, so the
+// whole set can be sent again. Cancelling ctx aborts the stream.
+func (c *GrpcClient) BatchDeleteStream(ctx context.Context, collection string, ids <-chan interface{}) (int, error) {
+	if err := checkCollectionName(c.namePattern, collection); err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.BatchDelete(ctx)
+	if err != nil {
+		return 0, grpcError(err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case id, ok := <-ids:
+			if !ok {
+				resp, err := stream.CloseAndRecv()
+				if err != nil {
+					return 0, grpcError(err)
+				}
+				return int(resp.Deleted), nil
+			}
+			err := stream.Send(&pb.DeleteDocumentRequest{Collection: collection, Id: grpcID(id)})
+			if errors.Is(err, io.EOF) {
+				// The server ended the stream; its status says why.
+				if _, err = stream.CloseAndRecv(); err == nil {
+					err = errors.New("barq: server ended the delete stream early")
+				}
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return 0, ctx.Err()
+				}
+				return 0, grpcError(err)
+			}
+		}
+	}
+}
+
+// grpcID renders a document ID for the proto's string id fields.
+func grpcID(id interface{}) string {
+	return fmt.Sprintf("%v", id)
 }
 
 func (c *GrpcClient) Search(ctx context.Context, collection string, vector []float32, topK int) ([]SearchResult, error) {
+	if err := checkCollectionName(c.namePattern, collection); err != nil {
+		return nil, err
+	}
+	if err := checkVector(vector); err != nil {
+		return nil, err
+	}
 	resp, err := c.client.Search(ctx, &pb.SearchRequest{
 		Collection: collection,
 		Vector:     vector,
 		TopK:       uint32(topK),
 	})
 	if err != nil {
-		return nil, err
+		return nil, grpcError(err)
 	}
 
-	var results []SearchResult
+	results := make([]SearchResult, 0, len(resp.Results))
 	for _, r := range resp.Results {
-		results = append(results, SearchResult{
-			ID:    r.Id,
-			Score: r.Score, // Proto definition must enable Score
-		})
+		result := SearchResult{ID: r.Id, Score: r.Score}
+		if r.PayloadJson != "" {
+			result.Payload = json.RawMessage(r.PayloadJson)
+		}
+		results = append(results, result)
 	}
+	// gRPC has no describe call; collections not created through this
+	// client report Similarity equal to Score.
+	metric, _ := c.metrics.get(collection)
+	setSimilarity(results, metric)
 	return results, nil
 }
+
+// SearchRaw sends req as is and returns the proto response untouched. It is
+// a lower-level escape hatch for fields the typed Search does not surface
+// yet; prefer Search otherwise, as this ties callers to the generated proto
+// types. Errors are converted to *APIError like everywhere else.
+func (c *GrpcClient) SearchRaw(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	resp, err := c.client.Search(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+// GetDocument fetches a stored document, like Client.GetDocument. The ID
+// comes back as the proto's string, and the payload as its raw JSON. A
+// missing document yields an error matching ErrNotFound.
+func (c *GrpcClient) GetDocument(ctx context.Context, collection string, id interface{}) (*Document, error) {
+	if err := checkCollectionName(c.namePattern, collection); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.GetDocument(ctx, &pb.GetDocumentRequest{Collection: collection, Id: grpcID(id)})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if !resp.Found {
+		return nil, fmt.Errorf("document %v in %q: %w", id, collection, ErrNotFound)
+	}
+
+	doc := &Document{ID: resp.Id, Vector: resp.Vector}
+	switch {
+	case len(resp.Payload) > 0:
+		doc.Payload = json.RawMessage(resp.Payload)
+	case resp.PayloadJson != "":
+		doc.Payload = json.RawMessage(resp.PayloadJson)
+	}
+	return doc, nil
+}
+
+// ListCollections returns every collection with its name, dimension and
+// metric; the gRPC service does not report schemas or index settings. The
+// metrics are remembered for Search's Similarity.
+func (c *GrpcClient) ListCollections(ctx context.Context) ([]CollectionInfo, error) {
+	resp, err := c.client.ListCollections(ctx, &pb.ListCollectionsRequest{})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	infos := make([]CollectionInfo, 0, len(resp.Collections))
+	for _, col := range resp.Collections {
+		info := CollectionInfo{Name: col.Name, Dimension: int(col.Dimension), Metric: Metric(col.Metric)}
+		c.metrics.set(info.Name, info.Metric)
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
@@ -0,0 +1,401 @@
+// Package barqtest runs an in-memory stand-in for a Barq server that serves
+// the HTTP API and the gRPC service from one store, so tests can write over
+// one transport and read over the other without a real server.
+//
+// It covers creating, describing and listing collections, inserting and
+// fetching documents, and unfiltered vector search, scored like the server:
+// L2 as a negated Euclidean distance, Cosine as cosine similarity and Dot as
+// the inner product. Unlike the server, it returns stored payloads with
+// search results over both transports. Anything else gets a 404 over HTTP
+// and Unimplemented over gRPC.
+package barqtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	pb "github.com/YASSERRMD/barq-db/barq-sdk-go/proto/barq"
+	"google.golang.org/grpc"
+)
+
+// Server is a running fake server. Start one with NewServer and stop it
+// with Close.
+type Server struct {
+	// URL is the base URL of the HTTP API, for Config.BaseURL.
+	URL string
+	// GRPCAddr is the address of the gRPC service, for NewGrpcClient.
+	GRPCAddr string
+
+	http *httptest.Server
+	grpc *grpc.Server
+
+	mu          sync.Mutex
+	collections map[string]*collection
+}
+
+type collection struct {
+	name      string
+	dimension int
+	metric    string
+	docs      []*document
+	byID      map[interface{}]*document
+}
+
+// document IDs are uint64 or string, as the server's U64 and Str.
+type document struct {
+	id      interface{}
+	vector  []float32
+	payload json.RawMessage
+}
+
+// NewServer starts a fake server listening on loopback. Like
+// httptest.NewServer, it panics if it cannot listen.
+func NewServer() *Server {
+	s := &Server{collections: map[string]*collection{}}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("barqtest: failed to listen: %v", err))
+	}
+	s.grpc = grpc.NewServer()
+	pb.RegisterBarqServer(s.grpc, &grpcService{s: s})
+	go s.grpc.Serve(lis)
+	s.GRPCAddr = lis.Addr().String()
+
+	s.http = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.http.URL
+	return s
+}
+
+// Close stops both listeners.
+func (s *Server) Close() {
+	s.grpc.Stop()
+	s.http.Close()
+}
+
+// errStatus pairs a message with the HTTP status it is reported with; the
+// gRPC service maps the same statuses to codes.
+type errStatus struct {
+	status int
+	msg    string
+}
+
+func (e *errStatus) Error() string { return e.msg }
+
+func errorf(status int, format string, args ...interface{}) error {
+	return &errStatus{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+func canonicalMetric(metric string) string {
+	switch strings.ToUpper(metric) {
+	case "COSINE":
+		return "Cosine"
+	case "DOT":
+		return "Dot"
+	default:
+		return "L2"
+	}
+}
+
+// parseID reads an ID given as text, from a URL path or the gRPC string
+// field, the way the server does: an unsigned integer if it parses as one.
+func parseID(s string) interface{} {
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u
+	}
+	return s
+}
+
+func (s *Server) createCollection(name string, dimension int, metric string) error {
+	if strings.TrimSpace(name) == "" {
+		return errorf(http.StatusBadRequest, "collection name must not be empty")
+	}
+	if dimension <= 0 {
+		return errorf(http.StatusBadRequest, "dimension must be positive")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[name]; ok {
+		return errorf(http.StatusConflict, "collection %s already exists", name)
+	}
+	s.collections[name] = &collection{
+		name:      name,
+		dimension: dimension,
+		metric:    canonicalMetric(metric),
+		byID:      map[interface{}]*document{},
+	}
+	return nil
+}
+
+// collection returns the named collection; s.mu must be held.
+func (s *Server) collection(name string) (*collection, error) {
+	c, ok := s.collections[name]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "collection %s not found", name)
+	}
+	return c, nil
+}
+
+func (s *Server) insert(name string, doc *document, upsert bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(name)
+	if err != nil {
+		return err
+	}
+	if len(doc.vector) != c.dimension {
+		return errorf(http.StatusBadRequest, "vector has dimension %d, collection %s has %d", len(doc.vector), name, c.dimension)
+	}
+	if old, ok := c.byID[doc.id]; ok {
+		if !upsert {
+			return errorf(http.StatusConflict, "document %v already exists", doc.id)
+		}
+		*old = *doc
+		return nil
+	}
+	c.byID[doc.id] = doc
+	c.docs = append(c.docs, doc)
+	return nil
+}
+
+func (s *Server) get(name string, id interface{}) (*document, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(name)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok := c.byID[id]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "document %v not found", id)
+	}
+	copied := *doc
+	return &copied, nil
+}
+
+type hit struct {
+	doc   *document
+	score float32
+}
+
+// search ranks every document by score, best first, keeping insertion order
+// between equal scores.
+func (s *Server) search(name string, vector []float32, topK int) ([]hit, error) {
+	if topK <= 0 {
+		return nil, errorf(http.StatusBadRequest, "top_k must be positive")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(vector) != c.dimension {
+		return nil, errorf(http.StatusBadRequest, "vector has dimension %d, collection %s has %d", len(vector), name, c.dimension)
+	}
+	hits := make([]hit, len(c.docs))
+	for i, doc := range c.docs {
+		copied := *doc
+		hits[i] = hit{doc: &copied, score: score(c.metric, vector, doc.vector)}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+	if len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits, nil
+}
+
+func score(metric string, a, b []float32) float32 {
+	var dot, na, nb, dist float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		na += x * x
+		nb += y * y
+		dist += (x - y) * (x - y)
+	}
+	switch metric {
+	case "Cosine":
+		if na == 0 || nb == 0 {
+			return 0
+		}
+		return float32(dot / (math.Sqrt(na) * math.Sqrt(nb)))
+	case "Dot":
+		return float32(dot)
+	default:
+		return float32(-math.Sqrt(dist))
+	}
+}
+
+type collectionJSON struct {
+	Name      string `json:"name"`
+	Dimension int    `json:"dimension"`
+	Metric    string `json:"metric"`
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// Split the escaped path so names and IDs may contain an escaped slash.
+	var parts []string
+	for _, p := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		part, err := url.PathUnescape(p)
+		if err != nil {
+			writeError(w, errorf(http.StatusBadRequest, "bad path: %v", err))
+			return
+		}
+		parts = append(parts, part)
+	}
+
+	var resp interface{}
+	var err error
+	switch {
+	case len(parts) == 1 && parts[0] == "collections" && r.Method == http.MethodPost:
+		var req collectionJSON
+		if err = decodeBody(r, &req); err == nil {
+			err = s.createCollection(req.Name, req.Dimension, req.Metric)
+			resp = map[string]interface{}{}
+		}
+	case len(parts) == 1 && parts[0] == "collections" && r.Method == http.MethodGet:
+		resp = map[string]interface{}{"collections": s.listCollections()}
+	case len(parts) == 2 && parts[0] == "collections" && r.Method == http.MethodGet:
+		resp, err = s.describe(parts[1])
+	case len(parts) == 3 && parts[0] == "collections" && parts[2] == "documents" && r.Method == http.MethodPost:
+		resp, err = s.httpInsert(parts[1], r)
+	case len(parts) == 4 && parts[0] == "collections" && parts[2] == "documents" && r.Method == http.MethodGet:
+		var doc *document
+		if doc, err = s.get(parts[1], parseID(parts[3])); err == nil {
+			resp = map[string]interface{}{"document": documentJSON(doc)}
+		}
+	case len(parts) == 3 && parts[0] == "collections" && parts[2] == "search" && r.Method == http.MethodPost:
+		resp, err = s.httpSearch(parts[1], r)
+	default:
+		err = errorf(http.StatusNotFound, "barqtest: %s %s is not supported", r.Method, r.URL.Path)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if e, ok := err.(*errStatus); ok {
+		status = e.status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+func (s *Server) listCollections() []collectionJSON {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]collectionJSON, 0, len(s.collections))
+	for _, c := range s.collections {
+		out = append(out, collectionJSON{Name: c.name, Dimension: c.dimension, Metric: c.metric})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (s *Server) describe(name string) (*collectionJSON, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.collection(name)
+	if err != nil {
+		return nil, err
+	}
+	return &collectionJSON{Name: c.name, Dimension: c.dimension, Metric: c.metric}, nil
+}
+
+func (s *Server) httpInsert(name string, r *http.Request) (interface{}, error) {
+	var req struct {
+		ID             json.RawMessage `json:"id"`
+		Vector         []float32       `json:"vector"`
+		VectorEncoding string          `json:"vector_encoding"`
+		Payload        json.RawMessage `json:"payload"`
+		Upsert         bool            `json:"upsert"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if req.VectorEncoding != "" {
+		return nil, errorf(http.StatusBadRequest, "barqtest: vector_encoding %q is not supported", req.VectorEncoding)
+	}
+	var id interface{}
+	var str string
+	if err := json.Unmarshal(req.ID, &str); err == nil {
+		id = str
+	} else if u, err := strconv.ParseUint(string(bytes.TrimSpace(req.ID)), 10, 64); err == nil {
+		id = u
+	} else {
+		return nil, errorf(http.StatusBadRequest, "id must be a string or an unsigned integer")
+	}
+	doc := &document{id: id, vector: req.Vector, payload: normalizePayload(req.Payload)}
+	if err := s.insert(name, doc, req.Upsert); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"id": id}, nil
+}
+
+func (s *Server) httpSearch(name string, r *http.Request) (interface{}, error) {
+	var req struct {
+		Vector []float32       `json:"vector"`
+		TopK   int             `json:"top_k"`
+		Filter json.RawMessage `json:"filter"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if len(req.Filter) > 0 && string(req.Filter) != "null" {
+		return nil, errorf(http.StatusBadRequest, "barqtest: filters are not supported")
+	}
+	hits, err := s.search(name, req.Vector, req.TopK)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]map[string]interface{}, len(hits))
+	for i, h := range hits {
+		results[i] = map[string]interface{}{"id": h.doc.id, "score": h.score}
+		if h.doc.payload != nil {
+			results[i]["payload"] = h.doc.payload
+		}
+	}
+	return map[string]interface{}{"results": results}, nil
+}
+
+func documentJSON(doc *document) map[string]interface{} {
+	out := map[string]interface{}{"id": doc.id, "vector": doc.vector}
+	if doc.payload != nil {
+		out["payload"] = doc.payload
+	}
+	return out
+}
+
+// normalizePayload drops JSON null, so a missing payload reads back the
+// same over both transports.
+func normalizePayload(p json.RawMessage) json.RawMessage {
+	p = bytes.TrimSpace(p)
+	if len(p) == 0 || string(p) == "null" {
+		return nil
+	}
+	return append(json.RawMessage(nil), p...)
+}
@@ -0,0 +1,124 @@
+package barqtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	pb "github.com/YASSERRMD/barq-db/barq-sdk-go/proto/barq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcService serves the Server's store over the Barq gRPC service.
+type grpcService struct {
+	pb.UnimplementedBarqServer
+	s *Server
+}
+
+// grpcStatus converts a store error to the status the server would answer
+// with.
+func grpcStatus(err error) error {
+	var e *errStatus
+	if !errors.As(err, &e) {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Internal
+	switch e.status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	}
+	return status.Error(code, e.msg)
+}
+
+func (g *grpcService) Health(ctx context.Context, req *pb.HealthRequest) (*pb.HealthResponse, error) {
+	return &pb.HealthResponse{Ok: true, Version: "barqtest"}, nil
+}
+
+func (g *grpcService) CreateCollection(ctx context.Context, req *pb.CreateCollectionRequest) (*pb.CreateCollectionResponse, error) {
+	if err := g.s.createCollection(req.Name, int(req.Dimension), req.Metric); err != nil {
+		return nil, grpcStatus(err)
+	}
+	return &pb.CreateCollectionResponse{Success: true}, nil
+}
+
+func (g *grpcService) InsertDocument(ctx context.Context, req *pb.InsertDocumentRequest) (*pb.InsertDocumentResponse, error) {
+	if err := g.insert(req.Collection, req); err != nil {
+		return nil, err
+	}
+	return &pb.InsertDocumentResponse{Success: true}, nil
+}
+
+func (g *grpcService) BatchInsert(ctx context.Context, req *pb.BatchInsertRequest) (*pb.BatchInsertResponse, error) {
+	var inserted uint32
+	for _, doc := range req.Documents {
+		if err := g.insert(req.Collection, doc); err != nil {
+			return nil, err
+		}
+		inserted++
+	}
+	return &pb.BatchInsertResponse{Inserted: inserted}, nil
+}
+
+// insert stores a document, reading the bytes payload in preference to
+// payload_json as servers with binary_payload do.
+func (g *grpcService) insert(collection string, req *pb.InsertDocumentRequest) error {
+	payload := req.Payload
+	if len(payload) == 0 {
+		payload = []byte(req.PayloadJson)
+	}
+	if p := normalizePayload(payload); p != nil && !json.Valid(p) {
+		return status.Error(codes.InvalidArgument, "invalid JSON payload")
+	}
+	doc := &document{id: parseID(req.Id), vector: req.Vector, payload: normalizePayload(payload)}
+	if err := g.s.insert(collection, doc, false); err != nil {
+		return grpcStatus(err)
+	}
+	return nil
+}
+
+func (g *grpcService) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	hits, err := g.s.search(req.Collection, req.Vector, int(req.TopK))
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	results := make([]*pb.SearchResult, len(hits))
+	for i, h := range hits {
+		results[i] = &pb.SearchResult{Id: fmt.Sprint(h.doc.id), Score: h.score, PayloadJson: string(h.doc.payload)}
+	}
+	return &pb.SearchResponse{Results: results}, nil
+}
+
+func (g *grpcService) GetDocument(ctx context.Context, req *pb.GetDocumentRequest) (*pb.GetDocumentResponse, error) {
+	doc, err := g.s.get(req.Collection, parseID(req.Id))
+	var e *errStatus
+	if errors.As(err, &e) && e.status == http.StatusNotFound {
+		if _, err := g.s.describe(req.Collection); err != nil {
+			return nil, grpcStatus(err)
+		}
+		return &pb.GetDocumentResponse{Found: false}, nil
+	}
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	return &pb.GetDocumentResponse{
+		Found:       true,
+		Id:          fmt.Sprint(doc.id),
+		Vector:      doc.vector,
+		PayloadJson: string(doc.payload),
+	}, nil
+}
+
+func (g *grpcService) ListCollections(ctx context.Context, req *pb.ListCollectionsRequest) (*pb.ListCollectionsResponse, error) {
+	var out []*pb.CollectionSummary
+	for _, c := range g.s.listCollections() {
+		out = append(out, &pb.CollectionSummary{Name: c.Name, Dimension: uint32(c.Dimension), Metric: c.Metric})
+	}
+	return &pb.ListCollectionsResponse{Collections: out}, nil
+}
@@ -0,0 +1,231 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CollectionClient is a handle bound to a single collection. It shares the
+// parent Client's transport and configuration, so creating one is cheap.
+type CollectionClient struct {
+	client   *Client
+	name     string
+	defaults SearchRequest
+	// scope is AND-combined with the filter of every search; see WithScope.
+	scope interface{}
+}
+
+func (c *Client) Collection(name string) *CollectionClient {
+	return &CollectionClient{client: c, name: name}
+}
+
+func (cc *CollectionClient) Name() string {
+	return cc.name
+}
+
+// WithSearchDefaults returns a copy of the handle whose searches start from
+// defaults. A field set on the per-call request always wins; a zero field
+// falls back to the default. Slices and the filter are taken whole from one
+// side, never merged element-wise: a per-call Filter replaces the default
+// filter rather than being combined with it. PageToken and After are never
+// defaulted.
+func (cc *CollectionClient) WithSearchDefaults(defaults SearchRequest) *CollectionClient {
+	clone := *cc
+	clone.defaults = defaults
+	return &clone
+}
+
+// WithScope returns a copy of the handle whose searches only see documents
+// matching filter, such as a tenant filter. The scope is AND-combined with
+// each search's own filter, including one from WithSearchDefaults, so a
+// per-call filter narrows the scope but never replaces it. Scoping an
+// already scoped handle requires both filters. Only searches are scoped:
+// Get, Delete and ScanVectors address documents directly and ignore it.
+func (cc *CollectionClient) WithScope(filter interface{}) *CollectionClient {
+	clone := *cc
+	clone.scope = scopeFilter(cc.scope, filter)
+	return &clone
+}
+
+// Unscoped returns a copy of the handle without the WithScope filter, for
+// admin queries that must see every document. Search defaults are kept.
+func (cc *CollectionClient) Unscoped() *CollectionClient {
+	clone := *cc
+	clone.scope = nil
+	return &clone
+}
+
+// scopeFilter requires both scope and filter to match; either may be nil.
+func scopeFilter(scope, filter interface{}) interface{} {
+	switch {
+	case scope == nil:
+		return filter
+	case filter == nil:
+		return scope
+	}
+	return filterNode{"op": "and", "filters": []interface{}{scope, filter}}
+}
+
+func (cc *CollectionClient) searchRequest(req SearchRequest) SearchRequest {
+	d := cc.defaults
+	if req.Vector == nil {
+		req.Vector = d.Vector
+	}
+	if req.VectorField == "" {
+		req.VectorField = d.VectorField
+	}
+	if req.Query == "" {
+		req.Query = d.Query
+	}
+	if req.TextField == "" {
+		req.TextField = d.TextField
+	}
+	if req.MinShouldMatch == 0 {
+		req.MinShouldMatch = d.MinShouldMatch
+	}
+	if req.Fuzziness == 0 {
+		req.Fuzziness = d.Fuzziness
+	}
+	if !req.Highlight {
+		req.Highlight = d.Highlight
+	}
+	if !req.IncludeVector {
+		req.IncludeVector = d.IncludeVector
+	}
+	if !req.ScoreOnly {
+		req.ScoreOnly = d.ScoreOnly
+	}
+	if req.TopK == 0 {
+		req.TopK = d.TopK
+	}
+	if req.Filter == nil {
+		req.Filter = d.Filter
+	}
+	if req.FilterMode == "" {
+		req.FilterMode = d.FilterMode
+	}
+	if !req.NormalizeScores {
+		req.NormalizeScores = d.NormalizeScores
+	}
+	if req.TimeBudget == 0 {
+		req.TimeBudget = d.TimeBudget
+	}
+	if len(req.Facets) == 0 {
+		req.Facets = d.Facets
+	}
+	req.Filter = scopeFilter(cc.scope, req.Filter)
+	return req
+}
+
+func (cc *CollectionClient) Describe(ctx context.Context, opts ...CallOption) (*CollectionInfo, error) {
+	return cc.client.DescribeCollection(ctx, cc.name, opts...)
+}
+
+func (cc *CollectionClient) SetReadOnly(ctx context.Context, readOnly bool, opts ...CallOption) error {
+	return cc.client.SetCollectionReadOnly(ctx, cc.name, readOnly, opts...)
+}
+
+func (cc *CollectionClient) Insert(ctx context.Context, req InsertRequest, opts ...CallOption) error {
+	return cc.client.Insert(ctx, cc.name, req, opts...)
+}
+
+func (cc *CollectionClient) InsertRaw(ctx context.Context, doc json.RawMessage, opts ...CallOption) error {
+	return cc.client.InsertRaw(ctx, cc.name, doc, opts...)
+}
+
+func (cc *CollectionClient) InsertStruct(ctx context.Context, obj interface{}, opts ...CallOption) error {
+	return cc.client.InsertStruct(ctx, cc.name, obj, opts...)
+}
+
+func (cc *CollectionClient) InsertWithID(ctx context.Context, req InsertRequest, opts ...CallOption) (interface{}, error) {
+	return cc.client.InsertWithID(ctx, cc.name, req, opts...)
+}
+
+func (cc *CollectionClient) InsertAck(ctx context.Context, req InsertRequest, opts ...CallOption) (*InsertAck, error) {
+	return cc.client.InsertAck(ctx, cc.name, req, opts...)
+}
+
+func (cc *CollectionClient) Transaction(ctx context.Context) (*Txn, error) {
+	return cc.client.Transaction(ctx, cc.name)
+}
+
+func (cc *CollectionClient) BatchUpdatePayload(ctx context.Context, updates []PayloadUpdate, opts ...CallOption) (*InsertReport, error) {
+	return cc.client.BatchUpdatePayload(ctx, cc.name, updates, opts...)
+}
+
+func (cc *CollectionClient) DeleteExpired(ctx context.Context, opts ...CallOption) (int, error) {
+	return cc.client.DeleteExpired(ctx, cc.name, opts...)
+}
+
+func (cc *CollectionClient) Get(ctx context.Context, id interface{}, opts ...CallOption) (*Document, error) {
+	return cc.client.GetDocument(ctx, cc.name, id, opts...)
+}
+
+func (cc *CollectionClient) ScanVectors(ctx context.Context, batchSize int, opts ...CallOption) *VectorScanner {
+	return cc.client.ScanVectors(ctx, cc.name, batchSize, opts...)
+}
+
+func (cc *CollectionClient) Delete(ctx context.Context, id interface{}, opts ...CallOption) error {
+	return cc.client.DeleteDocument(ctx, cc.name, id, opts...)
+}
+
+func (cc *CollectionClient) Search(ctx context.Context, req SearchRequest, opts ...CallOption) ([]SearchResult, error) {
+	return cc.client.Search(ctx, cc.name, cc.searchRequest(req), opts...)
+}
+
+func (cc *CollectionClient) SearchWithMeta(ctx context.Context, req SearchRequest, opts ...CallOption) (*SearchResponse, error) {
+	return cc.client.SearchWithMeta(ctx, cc.name, cc.searchRequest(req), opts...)
+}
+
+func (cc *CollectionClient) SearchText(ctx context.Context, text string, req SearchRequest, opts ...CallOption) ([]SearchResult, error) {
+	return cc.client.SearchText(ctx, cc.name, text, cc.searchRequest(req), opts...)
+}
+
+func (cc *CollectionClient) SearchEach(ctx context.Context, req SearchRequest, fn func(SearchResult) error, opts ...CallOption) error {
+	return cc.client.SearchEach(ctx, cc.name, cc.searchRequest(req), fn, opts...)
+}
+
+func (cc *CollectionClient) SearchIter(ctx context.Context, req SearchRequest, opts ...CallOption) *SearchIterator {
+	return cc.client.SearchIter(ctx, cc.name, cc.searchRequest(req), opts...)
+}
+
+func (cc *CollectionClient) SearchAll(ctx context.Context, req SearchRequest, limit int, opts ...CallOption) ([]SearchResult, error) {
+	return cc.client.SearchAll(ctx, cc.name, cc.searchRequest(req), limit, opts...)
+}
+
+func (cc *CollectionClient) SearchPaged(ctx context.Context, req SearchRequest, page, pageSize int, opts ...CallOption) (*PagedResults, error) {
+	return cc.client.SearchPaged(ctx, cc.name, cc.searchRequest(req), page, pageSize, opts...)
+}
+
+func (cc *CollectionClient) SearchMMR(ctx context.Context, req SearchRequest, lambda float32, k int, opts ...CallOption) ([]SearchResult, error) {
+	return cc.client.SearchMMR(ctx, cc.name, cc.searchRequest(req), lambda, k, opts...)
+}
+
+func (cc *CollectionClient) SearchByID(ctx context.Context, id interface{}, topK int, excludeSelf bool, opts ...CallOption) ([]SearchResult, error) {
+	if topK == 0 {
+		topK = cc.defaults.TopK
+	}
+	return cc.client.searchByID(ctx, cc.name, id, topK, excludeSelf, cc.scope, opts...)
+}
+
+func (cc *CollectionClient) SearchMany(ctx context.Context, vectors [][]float32, topK int, opts ...CallOption) ([][]SearchResult, error) {
+	if topK == 0 {
+		topK = cc.defaults.TopK
+	}
+	if cc.scope != nil {
+		reqs := make([]SearchRequest, len(vectors))
+		for i, v := range vectors {
+			reqs[i] = SearchRequest{Vector: v, TopK: topK, Filter: cc.scope}
+		}
+		return cc.client.BatchSearch(ctx, cc.name, reqs, opts...)
+	}
+	return cc.client.SearchMany(ctx, cc.name, vectors, topK, opts...)
+}
+
+func (cc *CollectionClient) BatchSearch(ctx context.Context, reqs []SearchRequest, opts ...CallOption) ([][]SearchResult, error) {
+	merged := make([]SearchRequest, len(reqs))
+	for i, req := range reqs {
+		merged[i] = cc.searchRequest(req)
+	}
+	return cc.client.BatchSearch(ctx, cc.name, merged, opts...)
+}
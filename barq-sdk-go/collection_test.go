@@ -0,0 +1,127 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collectionServer keeps the collections created on it and answers a
+// create of an existing name with 409, as the server does. When dropFirst
+// is set, the first create is applied but its connection is then dropped
+// after a stall, as a proxy does when its upstream times out.
+type collectionServer struct {
+	mu        sync.Mutex
+	created   map[string]CreateCollectionRequest
+	creates   int
+	dropFirst bool
+}
+
+func (s *collectionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method == http.MethodGet {
+		req, ok := s.created[r.URL.Path[len("/collections/"):]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(CollectionInfo{Name: req.Name, Dimension: req.Dimension, Metric: Metric(req.Metric)})
+		return
+	}
+
+	var req CreateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.creates++
+	if _, ok := s.created[req.Name]; ok {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error": "collection already exists"}`))
+		return
+	}
+	s.created[req.Name] = req
+	if s.dropFirst && s.creates == 1 {
+		time.Sleep(20 * time.Millisecond)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}
+}
+
+func (s *collectionServer) createCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.creates
+}
+
+func newCollectionServer(t *testing.T, dropFirst bool, existing ...CreateCollectionRequest) (*httptest.Server, *collectionServer) {
+	t.Helper()
+	s := &collectionServer{created: map[string]CreateCollectionRequest{}, dropFirst: dropFirst}
+	for _, req := range existing {
+		s.created[req.Name] = req
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return srv, s
+}
+
+func TestCreateCollectionIfNotExistsOnConflict(t *testing.T) {
+	existing := CreateCollectionRequest{Name: "docs", Dimension: 4, Metric: "Cosine"}
+	srv, _ := newCollectionServer(t, false, existing)
+	c := NewClient(Config{BaseURL: srv.URL})
+
+	// The existing collection is described, not the request echoed back.
+	info, err := c.CreateCollectionWithInfo(context.Background(), CreateCollectionRequest{
+		Name: "docs", Dimension: 8, Metric: "L2", IfNotExists: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Dimension != 4 || info.Metric != "Cosine" {
+		t.Fatalf("got %+v, want the existing collection's settings", info)
+	}
+
+	err = c.CreateCollection(context.Background(), CreateCollectionRequest{Name: "docs", Dimension: 4})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("without IfNotExists: got %v, want ErrConflict", err)
+	}
+}
+
+func TestCreateCollectionIfNotExistsRetriedAfterTimeout(t *testing.T) {
+	srv, s := newCollectionServer(t, true)
+	c := retryClient(srv.URL)
+
+	info, err := c.CreateCollectionWithInfo(context.Background(), CreateCollectionRequest{
+		Name: "docs", Dimension: 4, Metric: "L2", IfNotExists: true,
+	})
+	if err != nil {
+		t.Fatalf("got %v, want the retry's 409 to resolve to the created collection", err)
+	}
+	if info.Name != "docs" || info.Dimension != 4 {
+		t.Fatalf("got %+v, want the collection the first attempt created", info)
+	}
+	if n := s.createCount(); n != 2 {
+		t.Fatalf("server saw %d creates, want the dropped one and its retry", n)
+	}
+}
+
+func TestCreateCollectionNotRetriedWithoutIfNotExists(t *testing.T) {
+	srv, s := newCollectionServer(t, true)
+	c := retryClient(srv.URL)
+
+	err := c.CreateCollection(context.Background(), CreateCollectionRequest{Name: "docs", Dimension: 4, Metric: "L2"})
+	if err == nil {
+		t.Fatal("create succeeded, want the dropped connection's error")
+	}
+	if n := s.createCount(); n != 1 {
+		t.Fatalf("server saw %d creates, want 1: a plain create must not be replayed", n)
+	}
+}
@@ -0,0 +1,151 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/YASSERRMD/barq-db/barq-sdk-go/barqtest"
+)
+
+// conformanceTargets returns the HTTP base URL and gRPC address the
+// conformance suite runs against: the real server named by
+// BARQ_CONFORMANCE_HTTP_URL and BARQ_CONFORMANCE_GRPC_ADDR when both are
+// set, and otherwise a barqtest fake. Runs against a real server leave their
+// uniquely named collection behind.
+func conformanceTargets(t *testing.T) (httpURL, grpcAddr string, real bool) {
+	httpURL, grpcAddr = os.Getenv("BARQ_CONFORMANCE_HTTP_URL"), os.Getenv("BARQ_CONFORMANCE_GRPC_ADDR")
+	if httpURL != "" && grpcAddr != "" {
+		return httpURL, grpcAddr, true
+	}
+	srv := barqtest.NewServer()
+	t.Cleanup(srv.Close)
+	return srv.URL, srv.GRPCAddr, false
+}
+
+// TestTransportConformance writes documents over both transports and checks
+// that every search and fetch agrees between them, whichever transport
+// wrote the document.
+func TestTransportConformance(t *testing.T) {
+	httpURL, grpcAddr, real := conformanceTargets(t)
+	ctx := context.Background()
+	c := NewClient(Config{BaseURL: httpURL})
+	g, err := NewGrpcClient(grpcAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+
+	name := fmt.Sprintf("conformance-%d", time.Now().UnixNano())
+	if err := c.CreateCollection(ctx, CreateCollectionRequest{Name: name, Dimension: 3, Metric: "Cosine"}); err != nil {
+		t.Fatal(err)
+	}
+
+	type doc struct {
+		id      interface{}
+		vector  []float32
+		payload json.RawMessage
+	}
+	viaHTTP := []doc{
+		{1, []float32{1, 0, 0}, json.RawMessage(`{"title": "one", "tags": ["a", "b"]}`)},
+		{2, []float32{0.9, 0.1, 0}, nil},
+		{"doc-3", []float32{0, 1, 0}, json.RawMessage(`{"n": 3}`)},
+	}
+	viaGRPC := []doc{
+		{"4", []float32{0, 0.2, 0.9}, json.RawMessage(`{"title": "four"}`)},
+		{"doc-5", []float32{0.5, 0.5, 0.5}, json.RawMessage(`{"nested": {"x": [1, 2]}}`)},
+	}
+	for _, d := range viaHTTP {
+		if err := c.Insert(ctx, name, InsertRequest{ID: d.id, Vector: d.vector, Payload: d.payload, WaitForIndex: true}); err != nil {
+			t.Fatalf("HTTP insert %v: %v", d.id, err)
+		}
+	}
+	for _, d := range viaGRPC {
+		if err := g.InsertDocument(ctx, name, d.id, d.vector, d.payload, WaitForIndex()); err != nil {
+			t.Fatalf("gRPC insert %v: %v", d.id, err)
+		}
+	}
+	all := append(viaHTTP, viaGRPC...)
+
+	for _, q := range [][]float32{{1, 0, 0}, {0, 1, 0}, {0.3, 0.3, 0.9}} {
+		overHTTP, err := c.Search(ctx, name, SearchRequest{Vector: q, TopK: len(all)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		overGRPC, err := g.Search(ctx, name, q, len(all))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(overHTTP) != len(all) {
+			t.Fatalf("query %v: got %d results, want all %d documents", q, len(overHTTP), len(all))
+		}
+		if err := CompareResults(overHTTP, overGRPC, 1e-5); err != nil {
+			t.Errorf("query %v: transports disagree: %v", q, err)
+		}
+		if real {
+			continue
+		}
+		// The fake returns payloads with results, so check they are the
+		// stored ones and not just equal to each other.
+		for _, r := range overGRPC {
+			for _, d := range all {
+				if idKey(d.id) == idKey(r.ID) && !samePayload(r.Payload, d.payload) {
+					t.Errorf("query %v, id %v: got payload %s, want %s", q, r.ID, r.Payload, d.payload)
+				}
+			}
+		}
+	}
+
+	for _, d := range all {
+		fromHTTP, err := c.GetDocument(ctx, name, d.id)
+		if err != nil {
+			t.Fatalf("HTTP get %v: %v", d.id, err)
+		}
+		fromGRPC, err := g.GetDocument(ctx, name, d.id)
+		if err != nil {
+			t.Fatalf("gRPC get %v: %v", d.id, err)
+		}
+		for transport, got := range map[string]*Document{"HTTP": fromHTTP, "gRPC": fromGRPC} {
+			if idKey(got.ID) != idKey(d.id) {
+				t.Errorf("%s get %v: got id %v", transport, d.id, got.ID)
+			}
+			if fmt.Sprint(got.Vector) != fmt.Sprint(d.vector) {
+				t.Errorf("%s get %v: got vector %v, want %v", transport, d.id, got.Vector, d.vector)
+			}
+			if !samePayload(got.Payload, d.payload) {
+				t.Errorf("%s get %v: got payload %s, want %s", transport, d.id, got.Payload, d.payload)
+			}
+		}
+	}
+}
+
+func TestCompareResultsPayloads(t *testing.T) {
+	result := func(payload string) []SearchResult {
+		r := SearchResult{ID: 1, Score: 0.5}
+		if payload != "" {
+			r.Payload = json.RawMessage(payload)
+		}
+		return []SearchResult{r}
+	}
+	for _, pair := range [][2]string{
+		{`{"a": 1, "b": [true]}`, `{"b":[true],"a":1.0}`},
+		{"", `{}`},
+		{"", `null`},
+	} {
+		if err := CompareResults(result(pair[0]), result(pair[1]), 0); err != nil {
+			t.Errorf("%q vs %q: got %v, want a match", pair[0], pair[1], err)
+		}
+	}
+	for _, pair := range [][2]string{
+		{`{"a": 1}`, `{"a": 2}`},
+		{`{"a": 1}`, ""},
+		{`[1, 2]`, `[2, 1]`},
+	} {
+		if err := CompareResults(result(pair[0]), result(pair[1]), 0); err == nil {
+			t.Errorf("%q vs %q: got a match, want a payload difference", pair[0], pair[1])
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package barq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Default decode limits, used when the Config fields are zero.
+const (
+	DefaultMaxPayloadBytes = 16 << 20
+	DefaultMaxPayloadDepth = 64
+)
+
+// decode unmarshals a response body after checking its nesting depth
+// against Config.MaxPayloadDepth, so deeply nested input is rejected before
+// the decoder recurses into it. With Config.StrictDecode, fields v has no
+// place for fail with ErrUnknownField.
+func (c *Client) decode(data []byte, v interface{}) error {
+	if !c.config.StrictDecode {
+		return c.decodePayload(data, v)
+	}
+	if err := checkDepth(data, c.config.MaxPayloadDepth); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if strings.HasPrefix(err.Error(), "json: unknown field ") {
+			return fmt.Errorf("%w: %s", ErrUnknownField, strings.TrimPrefix(err.Error(), "json: "))
+		}
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("barq: unexpected data after response")
+	}
+	return nil
+}
+
+// decodePayload is decode without StrictDecode, for data whose shape is up
+// to the caller rather than the server, such as payloads.
+func (c *Client) decodePayload(data []byte, v interface{}) error {
+	if err := checkDepth(data, c.config.MaxPayloadDepth); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// checkPayloads decodes opaque payloads of decoded documents and enforces
+// Config.MaxPayloadBytes on them.
+func (c *Client) checkPayloads(docs ...*Document) error {
+	limit := c.config.MaxPayloadBytes
+	for _, doc := range docs {
+		if err := decodeOpaque(doc); err != nil {
+			return err
+		}
+		if limit > 0 && int64(len(doc.Payload)) > limit {
+			return fmt.Errorf("%w: document %v has %d bytes, limit %d", ErrPayloadTooLarge, doc.ID, len(doc.Payload), limit)
+		}
+	}
+	return nil
+}
+
+// checkResultPayloads enforces Config.MaxPayloadBytes on the payloads of
+// decoded search results.
+func (c *Client) checkResultPayloads(results []SearchResult) error {
+	limit := c.config.MaxPayloadBytes
+	for _, r := range results {
+		if limit > 0 && int64(len(r.Payload)) > limit {
+			return fmt.Errorf("%w: result %v has %d bytes, limit %d", ErrPayloadTooLarge, r.ID, len(r.Payload), limit)
+		}
+	}
+	return nil
+}
+
+// checkDepth reports ErrPayloadTooDeep if arrays and objects in data nest
+// more than limit levels deep. A limit <= 0 disables the check.
+func checkDepth(data []byte, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	depth := 0
+	inString, escaped := false, false
+	for _, b := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch b {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case b == '"':
+			inString = true
+		case b == '{' || b == '[':
+			depth++
+			if depth > limit {
+				return fmt.Errorf("%w: more than %d levels", ErrPayloadTooDeep, limit)
+			}
+		case b == '}' || b == ']':
+			depth--
+		}
+	}
+	return nil
+}
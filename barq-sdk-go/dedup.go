@@ -0,0 +1,119 @@
+package barq
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+	"sync"
+)
+
+// DedupWindow remembers the content hashes of the most recent documents
+// inserted with ConcurrencyOptions.Dedup, so duplicates are dropped across
+// calls as well as within one. It is safe for concurrent use.
+type DedupWindow struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front is most recently seen
+	entries map[string]*list.Element
+	// inflight holds the hashes of documents being inserted, each with a
+	// channel closed once the insert's outcome is known. They join entries
+	// only if the insert succeeds.
+	inflight map[string]chan struct{}
+}
+
+// NewDedupWindow returns a window of the last size hashes; a size <= 0
+// remembers every hash, which only suits imports of bounded size.
+func NewDedupWindow(size int) *DedupWindow {
+	return &DedupWindow{
+		size:     size,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]chan struct{}),
+	}
+}
+
+// claim reports whether the document hashed to key should be inserted,
+// and false for a copy of one already inserted. While another copy is being
+// inserted it waits for the outcome: a success makes this copy a duplicate,
+// a failure lets it claim the insert instead. A true result must be
+// followed by finish. It gives up with the context's error once ctx is
+// done.
+func (w *DedupWindow) claim(ctx context.Context, key string) (bool, error) {
+	for {
+		w.mu.Lock()
+		if el, ok := w.entries[key]; ok {
+			w.order.MoveToFront(el)
+			w.mu.Unlock()
+			return false, nil
+		}
+		wait, busy := w.inflight[key]
+		if !busy {
+			w.inflight[key] = make(chan struct{})
+			w.mu.Unlock()
+			return true, nil
+		}
+		w.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// finish records the outcome of the insert claimed for key. Only an
+// inserted document joins the window, so a later copy of one whose insert
+// failed is tried rather than skipped.
+func (w *DedupWindow) finish(key string, inserted bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if inserted {
+		w.entries[key] = w.order.PushFront(key)
+		if w.size > 0 && w.order.Len() > w.size {
+			oldest := w.order.Back()
+			w.order.Remove(oldest)
+			delete(w.entries, oldest.Value.(string))
+		}
+	}
+	close(w.inflight[key])
+	delete(w.inflight, key)
+}
+
+// contentHash hashes what makes a document's content: its collection,
+// vectors and payload, each part length-prefixed so they cannot run into
+// each other.
+func contentHash(collection string, req InsertRequest) string {
+	h := sha256.New()
+	var buf [8]byte
+	writePart := func(b []byte) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(len(b)))
+		h.Write(buf[:])
+		h.Write(b)
+	}
+	writeVector := func(v []float32) {
+		b := make([]byte, 4*len(v))
+		for i, f := range v {
+			binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(f))
+		}
+		writePart(b)
+	}
+
+	writePart([]byte(collection))
+	writeVector(req.Vector)
+	names := make([]string, 0, len(req.Vectors))
+	for name := range req.Vectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writePart([]byte(name))
+		writeVector(req.Vectors[name])
+	}
+	writePart([]byte(req.PayloadContentType))
+	writePart(req.Payload)
+	return string(h.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package barq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowInsertServer answers each insert after a delay, failing the first
+// failFirst of them with a 500, and counts the inserts it saw.
+func slowInsertServer(t *testing.T, failFirst int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var inserts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		n := atomic.AddInt32(&inserts, 1)
+		time.Sleep(20 * time.Millisecond)
+		if n <= failFirst {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "storage unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &inserts
+}
+
+func duplicateReqs(n int) []InsertRequest {
+	reqs := make([]InsertRequest, n)
+	for i := range reqs {
+		reqs[i] = InsertRequest{ID: i, Vector: []float32{1, 2}, Payload: []byte(`{"k": "v"}`)}
+	}
+	return reqs
+}
+
+func TestImportDedupSkipsCopiesOfInsertedDocument(t *testing.T) {
+	for _, concurrency := range []int{1, 3} {
+		srv, inserts := slowInsertServer(t, 0)
+		c := NewClient(Config{BaseURL: srv.URL})
+		report, err := c.InsertConcurrent(context.Background(), "docs", duplicateReqs(3),
+			WithImportConcurrency(concurrency), WithConcurrencyOptions(ConcurrencyOptions{Dedup: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Succeeded != 1 || report.Skipped != 2 || report.Failed != 0 {
+			t.Fatalf("concurrency %d: got %+v, want 1 imported and 2 skipped", concurrency, report)
+		}
+		for i, item := range report.Items {
+			if item.ID != i || (item.Status != ItemOK && item.Status != ItemSkipped) {
+				t.Fatalf("concurrency %d: item %d is %+v, want it inserted or skipped, in request order", concurrency, i, item)
+			}
+		}
+		if n := atomic.LoadInt32(inserts); n != 1 {
+			t.Fatalf("concurrency %d: server saw %d inserts, want 1", concurrency, n)
+		}
+	}
+}
+
+func TestImportDedupRetriesCopyOfFailedInsert(t *testing.T) {
+	// With several workers the copies arrive while the first insert is
+	// still in flight; they must wait for it rather than be skipped.
+	for _, concurrency := range []int{1, 3} {
+		srv, inserts := slowInsertServer(t, 1)
+		c := NewClient(Config{BaseURL: srv.URL})
+		report, err := c.InsertConcurrent(context.Background(), "docs", duplicateReqs(3),
+			WithImportConcurrency(concurrency), WithConcurrencyOptions(ConcurrencyOptions{Dedup: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Succeeded != 1 || report.Failed != 1 || report.Skipped != 1 {
+			t.Fatalf("concurrency %d: got %+v, want 1 imported, 1 failed and 1 skipped", concurrency, report)
+		}
+		if n := atomic.LoadInt32(inserts); n != 2 {
+			t.Fatalf("concurrency %d: server saw %d inserts, want the failed one and one copy", concurrency, n)
+		}
+	}
+}
+
+func TestDedupWindowClaimHonorsContext(t *testing.T) {
+	w := NewDedupWindow(0)
+	if claimed, err := w.claim(context.Background(), "k"); !claimed || err != nil {
+		t.Fatalf("got (%v, %v), want the first copy claimed", claimed, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if claimed, err := w.claim(ctx, "k"); claimed || err != context.DeadlineExceeded {
+		t.Fatalf("got (%v, %v) while the first copy was in flight, want the context's error", claimed, err)
+	}
+	w.finish("k", true)
+	if claimed, err := w.claim(context.Background(), "k"); claimed || err != nil {
+		t.Fatalf("got (%v, %v) after the first copy was inserted, want a duplicate", claimed, err)
+	}
+}
@@ -0,0 +1,109 @@
+package barq
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Embedder turns text into a query vector for SearchText.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface.
+type EmbedderFunc func(ctx context.Context, text string) ([]float32, error)
+
+func (f EmbedderFunc) Embed(ctx context.Context, text string) ([]float32, error) {
+	return f(ctx, text)
+}
+
+// SearchText embeds text with Config.Embedder and runs req as a vector
+// search with the result, replacing req.Vector. With Config.EmbeddingCacheSize
+// set, repeated texts reuse their cached embedding.
+func (c *Client) SearchText(ctx context.Context, collection, text string, req SearchRequest, opts ...CallOption) ([]SearchResult, error) {
+	vector, err := c.embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	req.Vector = vector
+	return c.Search(ctx, collection, req, opts...)
+}
+
+func (c *Client) embed(ctx context.Context, text string) ([]float32, error) {
+	if c.config.Embedder == nil {
+		return nil, errors.New("barq: SearchText needs Config.Embedder")
+	}
+	if c.embeddings == nil {
+		return c.config.Embedder.Embed(ctx, text)
+	}
+	if vector, ok := c.embeddings.get(text); ok {
+		return vector, nil
+	}
+	vector, err := c.config.Embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	c.embeddings.add(text, vector)
+	return vector, nil
+}
+
+// embeddingCache is an LRU of query embeddings keyed by text. Entries older
+// than ttl are treated as missing; a ttl <= 0 keeps them until evicted.
+type embeddingCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+type embeddingEntry struct {
+	text    string
+	vector  []float32
+	fetched time.Time
+}
+
+func newEmbeddingCache(size int, ttl time.Duration) *embeddingCache {
+	return &embeddingCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *embeddingCache) get(text string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[text]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*embeddingEntry)
+	if c.ttl > 0 && time.Since(e.fetched) > c.ttl {
+		c.order.Remove(el)
+		delete(c.entries, text)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.vector, true
+}
+
+func (c *embeddingCache) add(text string, vector []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[text]; ok {
+		el.Value = &embeddingEntry{text: text, vector: vector, fetched: time.Now()}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[text] = c.order.PushFront(&embeddingEntry{text: text, vector: vector, fetched: time.Now()})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*embeddingEntry).text)
+	}
+}
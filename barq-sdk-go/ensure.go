@@ -0,0 +1,124 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Schema declares the shape a collection is expected to have. Declare it
+// once next to the code that depends on it and pass it to EnsureSchema.
+type Schema struct {
+	Name          string
+	Dimension     int
+	Metric        Metric
+	TextFields    []TextField
+	PayloadFields []PayloadField
+	Vectors       []NamedVector
+}
+
+// SchemaMismatchError reports how an existing collection differs from the
+// Schema passed to EnsureSchema. It matches ErrSchemaMismatch.
+type SchemaMismatchError struct {
+	Collection string
+	// Diffs describes each difference, such as
+	// `dimension: want 768, have 384`.
+	Diffs []string
+}
+
+func (e *SchemaMismatchError) Error() string {
+	return fmt.Sprintf("barq: collection %q does not match its schema: %s", e.Collection, strings.Join(e.Diffs, "; "))
+}
+
+func (e *SchemaMismatchError) Is(target error) bool {
+	return target == ErrSchemaMismatch
+}
+
+// EnsureSchema creates the collection described by schema if it does not
+// exist, and otherwise checks that the existing one matches it: dimension,
+// metric, text fields, payload fields and named vectors. A mismatch returns
+// a *SchemaMismatchError listing every difference; nothing is changed on
+// the server. Call it at startup to catch drift between environments.
+func (c *Client) EnsureSchema(ctx context.Context, schema Schema, opts ...CallOption) (*CollectionInfo, error) {
+	info, err := c.DescribeCollection(ctx, schema.Name, opts...)
+	if errors.Is(err, ErrNotFound) {
+		// IfNotExists covers a concurrent create; its result is still
+		// checked below.
+		info, err = c.CreateCollectionWithInfo(ctx, CreateCollectionRequest{
+			Name:          schema.Name,
+			Dimension:     schema.Dimension,
+			Metric:        string(schema.Metric),
+			TextFields:    schema.TextFields,
+			PayloadFields: schema.PayloadFields,
+			Vectors:       schema.Vectors,
+			IfNotExists:   true,
+		}, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if diffs := schema.diff(info); len(diffs) > 0 {
+		return info, &SchemaMismatchError{Collection: schema.Name, Diffs: diffs}
+	}
+	return info, nil
+}
+
+func (s Schema) diff(info *CollectionInfo) []string {
+	var diffs []string
+	if info.Dimension != s.Dimension {
+		diffs = append(diffs, fmt.Sprintf("dimension: want %d, have %d", s.Dimension, info.Dimension))
+	}
+	if !strings.EqualFold(string(info.Metric), string(s.Metric)) {
+		diffs = append(diffs, fmt.Sprintf("metric: want %s, have %s", s.Metric, info.Metric))
+	}
+	diffs = append(diffs, diffNamed("text field", s.TextFields, info.TextFields,
+		func(f TextField) string { return f.Name }, diffFields[TextField])...)
+	diffs = append(diffs, diffNamed("payload field", s.PayloadFields, info.PayloadFields,
+		func(f PayloadField) string { return f.Name }, diffFields[PayloadField])...)
+	diffs = append(diffs, diffNamed("vector", s.Vectors, info.Vectors,
+		func(v NamedVector) string { return v.Name },
+		func(want, got NamedVector) string {
+			if got.Dimension == want.Dimension {
+				return ""
+			}
+			return fmt.Sprintf("want dimension %d, have %d", want.Dimension, got.Dimension)
+		})...)
+	return diffs
+}
+
+// diffNamed matches the entries of want and have by name and describes,
+// as "<kind> <name>: ...", each entry missing from have, each one not in
+// want and, through compare, each one that differs. compare returns "" for
+// entries that match.
+func diffNamed[T any](kind string, want, have []T, name func(T) string, compare func(want, got T) string) []string {
+	var diffs []string
+	byName := make(map[string]T, len(have))
+	for _, h := range have {
+		byName[name(h)] = h
+	}
+	for _, w := range want {
+		got, ok := byName[name(w)]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s %q: missing", kind, name(w)))
+		} else if d := compare(w, got); d != "" {
+			diffs = append(diffs, fmt.Sprintf("%s %q: %s", kind, name(w), d))
+		}
+		delete(byName, name(w))
+	}
+	for _, h := range have {
+		if _, extra := byName[name(h)]; extra {
+			diffs = append(diffs, fmt.Sprintf("%s %q: not in schema", kind, name(h)))
+		}
+	}
+	return diffs
+}
+
+// diffFields compares two field declarations as a whole.
+func diffFields[T comparable](want, got T) string {
+	if got == want {
+		return ""
+	}
+	return fmt.Sprintf("want %+v, have %+v", want, got)
+}
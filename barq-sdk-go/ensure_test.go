@@ -0,0 +1,35 @@
+package barq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSchemaDiff(t *testing.T) {
+	schema := Schema{
+		Name:          "docs",
+		Dimension:     768,
+		Metric:        MetricCosine,
+		TextFields:    []TextField{{Name: "title", Indexed: true}, {Name: "body"}},
+		PayloadFields: []PayloadField{{Name: "year", Type: "int"}},
+		Vectors:       []NamedVector{{Name: "image", Dimension: 512}, {Name: "audio", Dimension: 128}},
+	}
+	info := &CollectionInfo{
+		Dimension:     384,
+		Metric:        "cosine",
+		TextFields:    []TextField{{Name: "title"}, {Name: "summary"}},
+		PayloadFields: []PayloadField{{Name: "year", Type: "int"}},
+		Vectors:       []NamedVector{{Name: "image", Dimension: 256}},
+	}
+	want := []string{
+		"dimension: want 768, have 384",
+		`text field "title": want {Name:title Indexed:true Required:false}, have {Name:title Indexed:false Required:false}`,
+		`text field "body": missing`,
+		`text field "summary": not in schema`,
+		`vector "image": want dimension 512, have 256`,
+		`vector "audio": missing`,
+	}
+	if got := schema.diff(info); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got diffs\n%q\nwant\n%q", got, want)
+	}
+}
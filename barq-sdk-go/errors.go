@@ -0,0 +1,211 @@
+package barq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNotFound matches, via errors.Is, any error reporting that a collection or
+// document does not exist.
+var ErrNotFound = errors.New("barq: not found")
+
+// ErrResponseTooLarge is returned when a response body exceeds
+// Config.MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("barq: response exceeds MaxResponseBytes")
+
+// ErrPayloadTooLarge and ErrPayloadTooDeep are returned when a decoded
+// response exceeds Config.MaxPayloadBytes or Config.MaxPayloadDepth.
+var (
+	ErrPayloadTooLarge = errors.New("barq: payload exceeds MaxPayloadBytes")
+	ErrPayloadTooDeep  = errors.New("barq: payload exceeds MaxPayloadDepth")
+)
+
+// ErrDocumentTooLarge is returned by GrpcClient.InsertBatch when a single
+// document exceeds the gRPC message size limit on its own.
+var ErrDocumentTooLarge = errors.New("barq: document exceeds the gRPC message size limit")
+
+// ErrUnindexedFilterField is returned when Config.ValidateFilters is set and
+// a search filter references a field the collection does not index.
+var ErrUnindexedFilterField = errors.New("barq: filter field is not indexed")
+
+// ErrUnknownVector is returned when an insert names a vector the collection
+// schema does not declare.
+var ErrUnknownVector = errors.New("barq: unknown named vector")
+
+// ErrUnknownTextField is returned when a search targets a text field the
+// collection's cached schema does not declare.
+var ErrUnknownTextField = errors.New("barq: unknown text field")
+
+// ErrDimensionMismatch is returned when a vector's length does not match the
+// dimension declared for it.
+var ErrDimensionMismatch = errors.New("barq: vector dimension mismatch")
+
+// ErrInvalidCollectionName is returned, without contacting the server, for a
+// collection name that does not match Config.CollectionNamePattern or the
+// pattern set with WithGrpcCollectionNamePattern.
+var ErrInvalidCollectionName = errors.New("barq: invalid collection name")
+
+// ErrSoftDeleteUnsupported is returned by a DeleteDocument call made with
+// SoftDelete when the server does not advertise soft deletes.
+var ErrSoftDeleteUnsupported = errors.New("barq: server does not support soft delete")
+
+// ErrTransactionsUnsupported is returned by Transaction when the server
+// does not advertise transactions.
+var ErrTransactionsUnsupported = errors.New("barq: server does not support transactions")
+
+// ErrTxnDone is returned by Txn methods called after Commit or Rollback.
+var ErrTxnDone = errors.New("barq: transaction already committed or rolled back")
+
+// ErrInvalidVector is returned, before anything is sent, for a vector with a
+// NaN or infinite component. The error names the component's index.
+var ErrInvalidVector = errors.New("barq: invalid vector")
+
+// ErrReadOnly matches, via errors.Is, writes rejected because the collection
+// is read-only, whether by the server or by Config.EnforceReadOnly.
+var ErrReadOnly = errors.New("barq: collection is read-only")
+
+// ErrSchemaMismatch matches the *SchemaMismatchError returned by
+// EnsureSchema when an existing collection differs from the schema.
+var ErrSchemaMismatch = errors.New("barq: collection does not match schema")
+
+// ErrUnknownField is returned, with Config.StrictDecode set, for a response
+// carrying a field the SDK does not know. The error names the field.
+var ErrUnknownField = errors.New("barq: unknown field in response")
+
+// ErrSchemaNotCached is returned by client-side checks that need collection
+// metadata this client has not cached yet.
+var ErrSchemaNotCached = errors.New("barq: collection metadata not cached")
+
+// ErrUnauthorized matches, via errors.Is, any error reporting missing or
+// invalid credentials.
+var ErrUnauthorized = errors.New("barq: unauthorized")
+
+// ErrConflict matches, via errors.Is, any error reporting that a write was
+// rejected because of a conflicting state, such as a failed IfVersion check.
+var ErrConflict = errors.New("barq: conflict")
+
+// APIError is returned when the server answers with a non-2xx status, or by
+// GrpcClient when a call fails with a gRPC status. For gRPC errors Code is
+// the status code, StatusCode its closest HTTP equivalent, Body the status
+// message, and errors.Unwrap returns the original status error.
+//
+// HTTP error bodies in the envelope
+//
+//	{"error": {"code": "...", "message": "...", "details": {...}}}
+//
+// are parsed into ErrorCode, Message and Details. Body always holds the raw
+// body, and the other three stay empty when it does not match.
+type APIError struct {
+	StatusCode int
+	Body       string
+	Code       codes.Code
+	ErrorCode  string
+	Message    string
+	Details    map[string]interface{}
+	err        error
+}
+
+func (e *APIError) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("api error %d (%s): %s", e.StatusCode, e.Code, e.Body)
+	}
+	if e.Message != "" {
+		if e.ErrorCode != "" {
+			return fmt.Sprintf("api error %d (%s): %s", e.StatusCode, e.ErrorCode, e.Message)
+		}
+		return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Body)
+}
+
+// httpError builds the *APIError for an HTTP error status, parsing body
+// when it is an error envelope.
+func httpError(statusCode int, body []byte) *APIError {
+	e := &APIError{StatusCode: statusCode, Body: string(body)}
+	var envelope struct {
+		Error *struct {
+			Code    string                 `json:"code"`
+			Message string                 `json:"message"`
+			Details map[string]interface{} `json:"details"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != nil {
+		e.ErrorCode = envelope.Error.Code
+		e.Message = envelope.Error.Message
+		e.Details = envelope.Error.Details
+	}
+	return e
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrReadOnly:
+		return e.ErrorCode == "read_only"
+	}
+	return false
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// grpcStatusHTTP maps gRPC status codes to HTTP statuses, following the
+// grpc-gateway conventions.
+var grpcStatusHTTP = map[codes.Code]int{
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// grpcError translates a gRPC status error into an *APIError so that both
+// transports share one error model. Other errors are returned unchanged.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	code, ok := grpcStatusHTTP[st.Code()]
+	if !ok {
+		code = http.StatusInternalServerError
+	}
+	return &APIError{StatusCode: code, Body: st.Message(), Code: st.Code(), err: err}
+}
@@ -0,0 +1,52 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// endpoints returns BaseURL followed by Config.FallbackURLs.
+func (c *Client) endpoints() []string {
+	return append([]string{c.config.BaseURL}, c.config.FallbackURLs...)
+}
+
+// endpoint returns the base URL requests currently go to first.
+func (c *Client) endpoint() string {
+	return c.endpoints()[atomic.LoadInt32(&c.activeEndpoint)]
+}
+
+// setActiveEndpoint makes endpoint n preferred, unless a concurrent
+// request already moved it away from old.
+func (c *Client) setActiveEndpoint(old, n int) {
+	if atomic.CompareAndSwapInt32(&c.activeEndpoint, int32(old), int32(n)) {
+		atomic.AddInt64(&c.stats.failovers, 1)
+	}
+}
+
+// canFailover reports whether a request that failed with err may be sent to
+// the next endpoint. A 503 or a failed dial means the server did not apply
+// it. Other network errors, 502 and 504 may hide a request that was applied,
+// so those fail over only for reads and for requests that are safe to
+// repeat: POST reads such as searches, idempotent calls and requests
+// carrying an idempotency key.
+func canFailover(method string, safe bool, err error) bool {
+	var apiErr *APIError
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.As(err, &apiErr):
+		if apiErr.StatusCode == http.StatusServiceUnavailable {
+			return true
+		}
+		if apiErr.StatusCode != http.StatusBadGateway && apiErr.StatusCode != http.StatusGatewayTimeout {
+			return false
+		}
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return true
+	}
+	return method == http.MethodGet || method == http.MethodHead || safe
+}
@@ -0,0 +1,41 @@
+package barq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSearchFailsOverAfterMidFlightFailure(t *testing.T) {
+	primary, _ := stallingServer(t, 1<<30)
+	var fallbackCalls int32
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fallbackCalls, 1)
+		if r.Method == http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"results": [{"id": 1, "score": 0.5}]}`))
+	}))
+	defer fallback.Close()
+	c := NewClient(Config{BaseURL: primary.URL, FallbackURLs: []string{fallback.URL}})
+
+	results, err := c.Search(context.Background(), "docs", SearchRequest{Vector: []float32{1, 2}, TopK: 5})
+	if err != nil {
+		t.Fatalf("got %v, want the search to move to the fallback", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %v, want the fallback's result", results)
+	}
+
+	atomic.StoreInt32(&fallbackCalls, 0)
+	c = NewClient(Config{BaseURL: primary.URL, FallbackURLs: []string{fallback.URL}})
+	if err := c.Insert(context.Background(), "docs", InsertRequest{ID: 1, Vector: []float32{1, 2}}); err == nil {
+		t.Fatal("insert succeeded, want the dropped connection's error")
+	}
+	if n := atomic.LoadInt32(&fallbackCalls); n != 0 {
+		t.Fatalf("fallback saw %d requests, want none: a plain insert must not be replayed", n)
+	}
+}
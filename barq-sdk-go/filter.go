@@ -0,0 +1,185 @@
+package barq
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Filter is a node in the server's filter grammar. Filters built with this
+// package can be passed as SearchRequest.Filter and combined freely; they
+// marshal to objects such as {"op": "eq", "field": "lang", "value": "go"}.
+type Filter interface {
+	json.Marshaler
+	node() filterNode
+}
+
+type filterNode map[string]interface{}
+
+func (f filterNode) node() filterNode { return f }
+
+func (f filterNode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}(f))
+}
+
+// FilterMode selects when a search applies its filter relative to the
+// vector search. The zero value leaves the choice to the server.
+type FilterMode string
+
+const (
+	// FilterPre restricts the candidate set before the vector search, so
+	// every match can be found; it is slower on broad filters.
+	FilterPre FilterMode = "pre"
+	// FilterPost runs the vector search first and filters its hits. It is
+	// faster, but a restrictive filter can leave fewer than TopK results.
+	FilterPost FilterMode = "post"
+)
+
+func compare(op, field string, value interface{}) Filter {
+	return filterNode{"op": op, "field": field, "value": value}
+}
+
+func Eq(field string, value interface{}) Filter  { return compare("eq", field, value) }
+func Ne(field string, value interface{}) Filter  { return compare("ne", field, value) }
+func Gt(field string, value interface{}) Filter  { return compare("gt", field, value) }
+func Gte(field string, value interface{}) Filter { return compare("gte", field, value) }
+func Lt(field string, value interface{}) Filter  { return compare("lt", field, value) }
+func Lte(field string, value interface{}) Filter { return compare("lte", field, value) }
+
+// Exists matches documents whose payload has field, even when its value is
+// null. Not(Exists(f)) matches documents that lack it, such as ones written
+// before the field was added.
+func Exists(field string) Filter {
+	return filterNode{"op": "exists", "field": field}
+}
+
+// IsNull matches documents whose field is present and null. To match a field
+// that is missing or null, use Or(Not(Exists(f)), IsNull(f)).
+func IsNull(field string) Filter {
+	return compare("eq", field, nil)
+}
+
+// In matches documents whose field equals any of values.
+func In(field string, values ...interface{}) Filter {
+	if values == nil {
+		values = []interface{}{}
+	}
+	return filterNode{"op": "in", "field": field, "values": values}
+}
+
+// And matches when every filter matches; with no filters it matches all.
+func And(filters ...Filter) Filter {
+	return filterNode{"op": "and", "filters": nodes(filters)}
+}
+
+// Or matches when any filter matches; with no filters it matches none.
+func Or(filters ...Filter) Filter {
+	return filterNode{"op": "or", "filters": nodes(filters)}
+}
+
+func Not(filter Filter) Filter {
+	return filterNode{"op": "not", "filter": filter.node()}
+}
+
+// Boost ranks documents matching clause higher without filtering anything
+// out: the server multiplies the score of every document matching clause by
+// factor, and documents matched by several boosts get the product of their
+// factors. A factor above 1 promotes, below 1 demotes. Inside a BoolFilter,
+// boosts passed to Should stay optional and do not count towards the
+// "at least one Should clause" requirement.
+//
+// factor must be positive; otherwise encoding the filter fails, so the
+// search using it returns an error.
+func Boost(clause Filter, factor float64) Filter {
+	if factor <= 0 {
+		return invalidFilter{fmt.Errorf("barq: boost factor must be positive, got %v", factor)}
+	}
+	return filterNode{"op": "boost", "filter": clause.node(), "factor": factor}
+}
+
+// invalidFilter carries a construction error to the point the filter is
+// encoded.
+type invalidFilter struct {
+	err error
+}
+
+func (f invalidFilter) node() filterNode { return filterNode{"op": "invalid", "error": f} }
+
+func (f invalidFilter) MarshalJSON() ([]byte, error) { return nil, f.err }
+
+func isBoost(f Filter) bool {
+	return f.node()["op"] == "boost"
+}
+
+func nodes(filters []Filter) []filterNode {
+	out := make([]filterNode, len(filters))
+	for i, f := range filters {
+		out[i] = f.node()
+	}
+	return out
+}
+
+// BoolFilter composes clauses in the style of an Elasticsearch bool query:
+//
+//	Must:    every clause must match.
+//	Should:  at least one clause must match; Boost clauses only rank.
+//	MustNot: no clause may match.
+//
+// Empty groups are left out, so Bool() alone matches everything. The result
+// is an "and" of the non-empty groups, in the order must, boosts, should,
+// must_not; a group or an "and" holding a single clause is emitted as that
+// clause.
+type BoolFilter struct {
+	must    []Filter
+	should  []Filter
+	mustNot []Filter
+}
+
+func Bool() *BoolFilter {
+	return &BoolFilter{}
+}
+
+func (b *BoolFilter) Must(clauses ...Filter) *BoolFilter {
+	b.must = append(b.must, clauses...)
+	return b
+}
+
+func (b *BoolFilter) Should(clauses ...Filter) *BoolFilter {
+	b.should = append(b.should, clauses...)
+	return b
+}
+
+func (b *BoolFilter) MustNot(clauses ...Filter) *BoolFilter {
+	b.mustNot = append(b.mustNot, clauses...)
+	return b
+}
+
+func (b *BoolFilter) node() filterNode {
+	parts := append([]Filter(nil), b.must...)
+	var should []Filter
+	for _, f := range b.should {
+		if isBoost(f) {
+			parts = append(parts, f)
+		} else {
+			should = append(should, f)
+		}
+	}
+	if len(should) > 0 {
+		parts = append(parts, single(Or, should))
+	}
+	if len(b.mustNot) > 0 {
+		parts = append(parts, Not(single(Or, b.mustNot)))
+	}
+	return single(And, parts).node()
+}
+
+func (b *BoolFilter) MarshalJSON() ([]byte, error) {
+	return b.node().MarshalJSON()
+}
+
+// single applies combine unless there is exactly one filter.
+func single(combine func(...Filter) Filter, filters []Filter) Filter {
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return combine(filters...)
+}
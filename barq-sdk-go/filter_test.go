@@ -0,0 +1,180 @@
+package barq
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func marshalFilter(t *testing.T, f Filter) string {
+	t.Helper()
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBoolFilterGroups(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Filter
+		want string
+	}{
+		{
+			name: "empty",
+			f:    Bool(),
+			want: `{"filters":[],"op":"and"}`,
+		},
+		{
+			name: "empty clause lists",
+			f:    Bool().Must().Should().MustNot(),
+			want: `{"filters":[],"op":"and"}`,
+		},
+		{
+			name: "single must",
+			f:    Bool().Must(Eq("tenant", "a")),
+			want: `{"field":"tenant","op":"eq","value":"a"}`,
+		},
+		{
+			name: "single should",
+			f:    Bool().Should(Eq("lang", "go")),
+			want: `{"field":"lang","op":"eq","value":"go"}`,
+		},
+		{
+			name: "single must_not",
+			f:    Bool().MustNot(Eq("deleted", true)),
+			want: `{"filter":{"field":"deleted","op":"eq","value":true},"op":"not"}`,
+		},
+		{
+			name: "all groups",
+			f: Bool().
+				Must(Eq("tenant", "a")).
+				Should(Eq("lang", "go"), Eq("lang", "rust")).
+				MustNot(Eq("deleted", true), Eq("hidden", true)),
+			want: `{"filters":[` +
+				`{"field":"tenant","op":"eq","value":"a"},` +
+				`{"filters":[{"field":"lang","op":"eq","value":"go"},{"field":"lang","op":"eq","value":"rust"}],"op":"or"},` +
+				`{"filter":{"filters":[{"field":"deleted","op":"eq","value":true},{"field":"hidden","op":"eq","value":true}],"op":"or"},"op":"not"}` +
+				`],"op":"and"}`,
+		},
+		{
+			name: "repeated calls append",
+			f:    Bool().Must(Eq("a", 1)).Must(Eq("b", 2)),
+			want: `{"filters":[{"field":"a","op":"eq","value":1},{"field":"b","op":"eq","value":2}],"op":"and"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marshalFilter(t, tt.f); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoolFilterNesting(t *testing.T) {
+	inner := Bool().Must(Eq("a", 1)).MustNot(Eq("b", 2))
+	f := Bool().Must(Bool().Should(inner, Eq("c", 3)))
+	want := `{"filters":[` +
+		`{"filters":[{"field":"a","op":"eq","value":1},{"filter":{"field":"b","op":"eq","value":2},"op":"not"}],"op":"and"},` +
+		`{"field":"c","op":"eq","value":3}` +
+		`],"op":"or"}`
+	if got := marshalFilter(t, f); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestBoolFilterDeepNesting(t *testing.T) {
+	const depth = 50
+	var f Filter = Eq("leaf", true)
+	for i := 0; i < depth; i++ {
+		f = Bool().MustNot(f)
+	}
+
+	var node map[string]interface{}
+	if err := json.Unmarshal([]byte(marshalFilter(t, f)), &node); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < depth; i++ {
+		if node["op"] != "not" {
+			t.Fatalf("level %d: op is %v, want not", i, node["op"])
+		}
+		node = node["filter"].(map[string]interface{})
+	}
+	if node["field"] != "leaf" {
+		t.Fatalf("innermost node is %v, want the leaf clause", node)
+	}
+}
+
+func TestBoolFilterStableEncoding(t *testing.T) {
+	f := Bool().Must(Eq("tenant", "a"), In("tag", "x", "y")).Should(Gt("score", 1))
+	first := marshalFilter(t, f)
+	for i := 0; i < 20; i++ {
+		if got := marshalFilter(t, f); got != first {
+			t.Fatalf("encoding changed between calls:\n%s\n%s", first, got)
+		}
+	}
+}
+
+func TestExistsAndIsNullCompose(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Filter
+		want string
+	}{
+		{
+			name: "exists",
+			f:    Exists("author"),
+			want: `{"field":"author","op":"exists"}`,
+		},
+		{
+			name: "is null",
+			f:    IsNull("author"),
+			want: `{"field":"author","op":"eq","value":null}`,
+		},
+		{
+			name: "missing",
+			f:    Not(Exists("author")),
+			want: `{"filter":{"field":"author","op":"exists"},"op":"not"}`,
+		},
+		{
+			name: "missing or null",
+			f:    Or(Not(Exists("author")), IsNull("author")),
+			want: `{"filters":[` +
+				`{"filter":{"field":"author","op":"exists"},"op":"not"},` +
+				`{"field":"author","op":"eq","value":null}` +
+				`],"op":"or"}`,
+		},
+		{
+			name: "present and not null",
+			f:    And(Exists("author"), Not(IsNull("author"))),
+			want: `{"filters":[` +
+				`{"field":"author","op":"exists"},` +
+				`{"filter":{"field":"author","op":"eq","value":null},"op":"not"}` +
+				`],"op":"and"}`,
+		},
+		{
+			name: "nested with comparisons",
+			f:    And(Eq("tenant", "a"), Or(IsNull("deleted_at"), Not(Exists("deleted_at")))),
+			want: `{"filters":[` +
+				`{"field":"tenant","op":"eq","value":"a"},` +
+				`{"filters":[{"field":"deleted_at","op":"eq","value":null},{"filter":{"field":"deleted_at","op":"exists"},"op":"not"}],"op":"or"}` +
+				`],"op":"and"}`,
+		},
+		{
+			name: "bool groups",
+			f:    Bool().Must(Exists("author")).MustNot(IsNull("author")),
+			want: `{"filters":[` +
+				`{"field":"author","op":"exists"},` +
+				`{"filter":{"field":"author","op":"eq","value":null},"op":"not"}` +
+				`],"op":"and"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := marshalFilter(t, tt.f); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package barq
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultRRFK is the rank constant RRF uses when k <= 0, the value from the
+// original reciprocal rank fusion paper.
+const DefaultRRFK = 60
+
+// RRF merges ranked result lists with reciprocal rank fusion. Each document
+// scores the sum of 1/(k+rank) over the lists it appears in, with rank
+// starting at 1, so documents ranked highly by several lists rise to the
+// top. Results are deduplicated by ID; a document repeated within one list
+// counts only at its best rank there.
+//
+// The returned results carry the fused score in both Score and Similarity.
+// Ties keep the order in which documents were first seen.
+func RRF(resultSets [][]SearchResult, k int) []SearchResult {
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+
+	index := make(map[string]int)
+	fused := []SearchResult{}
+	for _, results := range resultSets {
+		seen := make(map[string]bool)
+		for rank, r := range results {
+			key := idKey(r.ID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			i, ok := index[key]
+			if !ok {
+				i = len(fused)
+				index[key] = i
+				fused = append(fused, SearchResult{ID: r.ID})
+			}
+			fused[i].Score += 1 / float32(k+rank+1)
+		}
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+	for i := range fused {
+		fused[i].Similarity = fused[i].Score
+	}
+	return fused
+}
+
+// MMR reranks results with maximal marginal relevance and returns up to k of
+// them, trading relevance to query against redundancy among the picks. Each
+// step picks the result maximizing
+//
+//	lambda*sim(query, d) - (1-lambda)*max(sim(d, s) for s already picked)
+//
+// where sim is cosine similarity. lambda 1 keeps the relevance order and
+// lambda 0 maximizes diversity; values outside [0, 1], and a negative k,
+// are errors. Results need their vectors (SearchRequest.IncludeVector);
+// those without one are left out.
+func MMR(query []float32, results []SearchResult, lambda float32, k int) ([]SearchResult, error) {
+	if err := checkMMR(lambda, k); err != nil {
+		return nil, err
+	}
+
+	var candidates []SearchResult
+	var relevance []float32
+	for _, r := range results {
+		if len(r.Vector) > 0 {
+			candidates = append(candidates, r)
+			relevance = append(relevance, cosine(query, r.Vector))
+		}
+	}
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+
+	picked := make([]SearchResult, 0, k)
+	used := make([]bool, len(candidates))
+	// redundancy[i] is the highest similarity of candidate i to any pick.
+	redundancy := make([]float32, len(candidates))
+	for len(picked) < k {
+		best, bestScore := -1, float32(0)
+		for i := range candidates {
+			if used[i] {
+				continue
+			}
+			score := lambda * relevance[i]
+			if len(picked) > 0 {
+				score -= (1 - lambda) * redundancy[i]
+			}
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		used[best] = true
+		picked = append(picked, candidates[best])
+		for i := range candidates {
+			sim := cosine(candidates[i].Vector, candidates[best].Vector)
+			if len(picked) == 1 || sim > redundancy[i] {
+				redundancy[i] = sim
+			}
+		}
+	}
+	return picked, nil
+}
+
+func checkMMR(lambda float32, k int) error {
+	if !(lambda >= 0 && lambda <= 1) {
+		return fmt.Errorf("barq: MMR lambda must be in [0, 1], got %v", lambda)
+	}
+	if k < 0 {
+		return fmt.Errorf("barq: MMR k must not be negative, got %d", k)
+	}
+	return nil
+}
+
+// SearchMMR runs req with vectors included and reranks the candidates with
+// MMR against req.Vector. req.TopK is the candidate pool size; when it is
+// below k, 4*k candidates are fetched.
+func (c *Client) SearchMMR(ctx context.Context, collection string, req SearchRequest, lambda float32, k int, opts ...CallOption) ([]SearchResult, error) {
+	if err := checkMMR(lambda, k); err != nil {
+		return nil, err
+	}
+	if req.TopK < k {
+		req.TopK = 4 * k
+	}
+	req.IncludeVector = true
+	results, err := c.Search(ctx, collection, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return MMR(req.Vector, results, lambda, k)
+}
+
+func cosine(a, b []float32) float32 {
+	var dot, na, nb float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return float32(dot / math.Sqrt(na*nb))
+}
@@ -0,0 +1,143 @@
+package barq
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func hits(ids ...interface{}) []SearchResult {
+	out := make([]SearchResult, len(ids))
+	for i, id := range ids {
+		out[i] = SearchResult{ID: id, Score: float32(len(ids) - i)}
+	}
+	return out
+}
+
+func checkFused(t *testing.T, got []SearchResult, wantIDs []interface{}, wantScores []float64) {
+	t.Helper()
+	if len(got) != len(wantIDs) {
+		t.Fatalf("got %d results, want %d: %v", len(got), len(wantIDs), got)
+	}
+	for i := range got {
+		if idKey(got[i].ID) != idKey(wantIDs[i]) {
+			t.Errorf("rank %d: got ID %v, want %v", i, got[i].ID, wantIDs[i])
+		}
+		if math.Abs(float64(got[i].Score)-wantScores[i]) > 1e-6 {
+			t.Errorf("rank %d: got score %v, want %v", i, got[i].Score, wantScores[i])
+		}
+		if got[i].Similarity != got[i].Score {
+			t.Errorf("rank %d: Similarity %v differs from Score %v", i, got[i].Similarity, got[i].Score)
+		}
+	}
+}
+
+func TestRRFKnownRanking(t *testing.T) {
+	// d1 is 1st and 2nd, d3 is 3rd and 1st: 1/61+1/62 > 1/63+1/61.
+	got := RRF([][]SearchResult{
+		hits("d1", "d2", "d3"),
+		hits("d3", "d1", "d4"),
+	}, 60)
+	checkFused(t, got,
+		[]interface{}{"d1", "d3", "d2", "d4"},
+		[]float64{1.0/61 + 1.0/62, 1.0/63 + 1.0/61, 1.0 / 62, 1.0 / 63})
+}
+
+func TestRRFSmallK(t *testing.T) {
+	got := RRF([][]SearchResult{hits("a", "b"), hits("b", "c")}, 1)
+	checkFused(t, got,
+		[]interface{}{"b", "a", "c"},
+		[]float64{1.0/3 + 1.0/2, 1.0 / 2, 1.0 / 3})
+}
+
+func TestRRFDefaultK(t *testing.T) {
+	sets := [][]SearchResult{hits("a", "b"), hits("b", "a", "c")}
+	want := RRF(sets, DefaultRRFK)
+	for _, k := range []int{0, -5} {
+		got := RRF(sets, k)
+		checkFused(t, got, []interface{}{want[0].ID, want[1].ID, want[2].ID},
+			[]float64{float64(want[0].Score), float64(want[1].Score), float64(want[2].Score)})
+	}
+}
+
+func TestRRFDeduplicates(t *testing.T) {
+	// A repeat within a list counts at its best rank only, and IDs match by
+	// value across types, as server JSON decodes numbers to float64.
+	got := RRF([][]SearchResult{
+		hits(7, "x", 7),
+		hits(float64(7)),
+	}, 60)
+	checkFused(t, got,
+		[]interface{}{7, "x"},
+		[]float64{1.0/61 + 1.0/61, 1.0 / 62})
+}
+
+func TestRRFTiesKeepFirstSeenOrder(t *testing.T) {
+	got := RRF([][]SearchResult{hits("a", "b"), hits("b", "a")}, 60)
+	checkFused(t, got,
+		[]interface{}{"a", "b"},
+		[]float64{1.0/61 + 1.0/62, 1.0/62 + 1.0/61})
+}
+
+func TestRRFEmpty(t *testing.T) {
+	for _, sets := range [][][]SearchResult{nil, {}, {nil, {}}} {
+		if got := RRF(sets, 60); got == nil || len(got) != 0 {
+			t.Fatalf("RRF(%v): got %#v, want a non-nil empty slice", sets, got)
+		}
+	}
+}
+
+func TestMMRRejectsInvalidArguments(t *testing.T) {
+	results := []SearchResult{{ID: "a", Vector: []float32{1, 0}}}
+	tests := []struct {
+		name   string
+		lambda float32
+		k      int
+	}{
+		{"negative k", 0.5, -1},
+		{"lambda below 0", -0.1, 1},
+		{"lambda above 1", 1.5, 1},
+		{"lambda NaN", float32(math.NaN()), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := MMR([]float32{1, 0}, results, tt.lambda, tt.k); err == nil {
+				t.Fatal("got no error")
+			}
+			c := NewClient(Config{BaseURL: "http://127.0.0.1:0"})
+			if _, err := c.SearchMMR(context.Background(), "docs", SearchRequest{Vector: []float32{1, 0}}, tt.lambda, tt.k); err == nil {
+				t.Fatal("SearchMMR: got no error")
+			}
+		})
+	}
+}
+
+func TestMMRPicksDiverseResults(t *testing.T) {
+	query := []float32{1, 0}
+	results := []SearchResult{
+		{ID: "a", Vector: []float32{1, 0.1}},
+		{ID: "a-dup", Vector: []float32{1, 0.11}},
+		{ID: "b", Vector: []float32{0.7, -0.7}},
+		{ID: "no-vector"},
+	}
+
+	got, err := MMR(query, results, 0.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("got %v, want a then b", got)
+	}
+
+	got, err = MMR(query, results, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].ID != "a" || got[1].ID != "a-dup" {
+		t.Fatalf("lambda 1: got %v, want the relevance order without the vectorless result", got)
+	}
+
+	if got, err := MMR(query, results, 0.5, 0); err != nil || len(got) != 0 {
+		t.Fatalf("k 0: got (%v, %v), want no results", got, err)
+	}
+}
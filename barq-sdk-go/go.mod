@@ -1,5 +1,6 @@
 module github.com/YASSERRMD/barq-db/barq-sdk-go
 
+go 1.19
 
 require (
 	google.golang.org/grpc v1.59.0
@@ -0,0 +1,39 @@
+package barq
+
+import (
+	"fmt"
+
+	pb "github.com/YASSERRMD/barq-db/barq-sdk-go/proto/barq"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultGrpcMaxMsgSize is the message size limit InsertBatch assumes unless
+// WithGrpcMaxMsgSize says otherwise: 4MiB, grpc's default receive limit on
+// the server.
+const DefaultGrpcMaxMsgSize = 4 << 20
+
+// chunkDocuments splits docs into consecutive groups whose BatchInsertRequest
+// encodes to at most limit bytes.
+func chunkDocuments(collection string, docs []*pb.InsertDocumentRequest, limit int) ([][]*pb.InsertDocumentRequest, error) {
+	base := proto.Size(&pb.BatchInsertRequest{Collection: collection})
+	var chunks [][]*pb.InsertDocumentRequest
+	var chunk []*pb.InsertDocumentRequest
+	size := base
+	for _, doc := range docs {
+		n := protowire.SizeTag(2) + protowire.SizeBytes(proto.Size(doc))
+		if base+n > limit {
+			return nil, fmt.Errorf("%w: document %q needs %d bytes, limit %d", ErrDocumentTooLarge, doc.Id, base+n, limit)
+		}
+		if size+n > limit {
+			chunks = append(chunks, chunk)
+			chunk, size = nil, base
+		}
+		chunk = append(chunk, doc)
+		size += n
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
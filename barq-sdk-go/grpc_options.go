@@ -0,0 +1,270 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// GrpcOption configures a GrpcClient created by NewGrpcClient.
+type GrpcOption func(*grpcOptions)
+
+type grpcOptions struct {
+	apiKey      string
+	authHeader  string
+	authScheme  string
+	timeout     time.Duration
+	unary       []grpc.UnaryClientInterceptor
+	stream      []grpc.StreamClientInterceptor
+	retry       *GrpcRetryPolicy
+	lbPolicy    string
+	namePattern *regexp.Regexp
+	window      int32
+	maxMsgSize  int
+	connWindow  int32
+	dialOptions []grpc.DialOption
+
+	contextHeaders []ContextHeader
+}
+
+// WithGrpcAPIKey sends key as x-api-key metadata on every call, or under the
+// key set by WithGrpcAuthHeader.
+func WithGrpcAPIKey(key string) GrpcOption {
+	return func(o *grpcOptions) { o.apiKey = key }
+}
+
+// WithGrpcAuthHeader changes the metadata key the API key is sent under and
+// an optional scheme prefix, mirroring Config.AuthHeader and
+// Config.AuthScheme. gRPC metadata keys are lowercase, so the name is
+// lowercased.
+func WithGrpcAuthHeader(name, scheme string) GrpcOption {
+	return func(o *grpcOptions) {
+		o.authHeader = strings.ToLower(name)
+		o.authScheme = scheme
+	}
+}
+
+// WithGrpcTimeout sets the deadline applied to unary calls whose context has
+// none, mirroring Config.Timeout. It defaults to DefaultTimeout; a value
+// <= 0 disables it. Streaming calls are never given a default deadline.
+//
+// A deadline already on the caller's context always wins, whether it is
+// shorter or longer. The deadline bounds the whole call, including any
+// attempts made under WithGrpcRetry.
+func WithGrpcTimeout(d time.Duration) GrpcOption {
+	return func(o *grpcOptions) { o.timeout = d }
+}
+
+// GrpcRetryPolicy is the retry policy installed by WithGrpcRetry. Zero fields
+// take the defaults noted below.
+type GrpcRetryPolicy struct {
+	MaxAttempts          int           // including the first; default 3, grpc caps it at 5
+	InitialBackoff       time.Duration // default 100ms
+	MaxBackoff           time.Duration // default 2s
+	BackoffMultiplier    float64       // default 2
+	RetryableStatusCodes []codes.Code  // default Unavailable, ResourceExhausted
+}
+
+// WithGrpcRetry retries failed calls to the Barq service according to
+// policy, using grpc's built-in retry support via the default service
+// config. A service config supplied by the resolver takes precedence.
+func WithGrpcRetry(policy GrpcRetryPolicy) GrpcOption {
+	return func(o *grpcOptions) { o.retry = &policy }
+}
+
+// WithLoadBalancing sets the channel's load-balancing policy through the
+// default service config, such as "round_robin" to spread calls over every
+// address the resolver returns, or "pick_first", grpc's default. It only
+// balances when the target resolves to several addresses, as a
+// dns:///host:port target for a headless service does; an unknown policy
+// makes NewGrpcClient fail.
+func WithLoadBalancing(policy string) GrpcOption {
+	return func(o *grpcOptions) { o.lbPolicy = policy }
+}
+
+// serviceConfig merges the retry and load-balancing settings into one
+// default service config, since grpc keeps only the last one given. It is
+// empty when neither is set.
+func (o *grpcOptions) serviceConfig() string {
+	cfg := map[string]interface{}{}
+	if o.retry != nil {
+		cfg["methodConfig"] = []interface{}{o.retry.methodConfig()}
+	}
+	if o.lbPolicy != "" {
+		cfg["loadBalancingConfig"] = []interface{}{map[string]interface{}{o.lbPolicy: map[string]interface{}{}}}
+	}
+	if len(cfg) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(cfg)
+	return string(b)
+}
+
+func (p GrpcRetryPolicy) methodConfig() map[string]interface{} {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = 3
+	}
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = 2 * time.Second
+	}
+	if p.BackoffMultiplier == 0 {
+		p.BackoffMultiplier = 2
+	}
+	if len(p.RetryableStatusCodes) == 0 {
+		p.RetryableStatusCodes = []codes.Code{codes.Unavailable, codes.ResourceExhausted}
+	}
+	return map[string]interface{}{
+		"name": []interface{}{map[string]string{"service": "barq.Barq"}},
+		"retryPolicy": map[string]interface{}{
+			"maxAttempts":          p.MaxAttempts,
+			"initialBackoff":       durationString(p.InitialBackoff),
+			"maxBackoff":           durationString(p.MaxBackoff),
+			"backoffMultiplier":    p.BackoffMultiplier,
+			"retryableStatusCodes": p.RetryableStatusCodes,
+		},
+	}
+}
+
+// durationString formats d the way service config JSON expects, e.g. "0.1s".
+func durationString(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// WithGrpcWindowSize sets the HTTP/2 flow-control windows in bytes: stream
+// for each call and conn for the whole channel. Values below 64KiB are
+// ignored by grpc, and setting a window disables grpc's dynamic window
+// sizing (BDP estimation) for it. Zero leaves a window at its default.
+//
+// For many concurrent searches with large responses over one channel, a
+// stream window of 1MiB and a connection window of 16MiB or more keeps
+// calls from stalling on each other.
+func WithGrpcWindowSize(stream, conn int32) GrpcOption {
+	return func(o *grpcOptions) {
+		o.window = stream
+		o.connWindow = conn
+	}
+}
+
+// WithGrpcCollectionNamePattern sets the rule collection names are checked
+// against before any call, mirroring Config.CollectionNamePattern; nil means
+// DefaultCollectionNamePattern.
+func WithGrpcCollectionNamePattern(pattern *regexp.Regexp) GrpcOption {
+	return func(o *grpcOptions) { o.namePattern = pattern }
+}
+
+// WithGrpcMaxMsgSize sets the largest message the client sends, in bytes.
+// It should match the server's receive limit: InsertBatch splits batches to
+// stay under it. The default is DefaultGrpcMaxMsgSize.
+func WithGrpcMaxMsgSize(n int) GrpcOption {
+	return func(o *grpcOptions) { o.maxMsgSize = n }
+}
+
+// WithUnaryInterceptor appends interceptors to the unary chain. Built-in
+// interceptors (default timeout, API key) run first, so user interceptors
+// observe the outgoing metadata they add; user interceptors run in the order
+// given, across repeated options.
+func WithUnaryInterceptor(interceptors ...grpc.UnaryClientInterceptor) GrpcOption {
+	return func(o *grpcOptions) { o.unary = append(o.unary, interceptors...) }
+}
+
+// WithStreamInterceptor appends interceptors to the stream chain, with the
+// same ordering as WithUnaryInterceptor.
+func WithStreamInterceptor(interceptors ...grpc.StreamClientInterceptor) GrpcOption {
+	return func(o *grpcOptions) { o.stream = append(o.stream, interceptors...) }
+}
+
+// WithDialOptions passes raw dial options through to grpc.Dial after the
+// SDK's own.
+func WithDialOptions(opts ...grpc.DialOption) GrpcOption {
+	return func(o *grpcOptions) { o.dialOptions = append(o.dialOptions, opts...) }
+}
+
+func (o *grpcOptions) buildDialOptions() []grpc.DialOption {
+	var unary []grpc.UnaryClientInterceptor
+	var stream []grpc.StreamClientInterceptor
+	if o.timeout > 0 {
+		unary = append(unary, timeoutUnaryInterceptor(o.timeout))
+	}
+	unary = append(unary, apiKeyUnaryInterceptor(o.authHeader, o.authScheme, o.apiKey))
+	stream = append(stream, apiKeyStreamInterceptor(o.authHeader, o.authScheme, o.apiKey))
+	if len(o.contextHeaders) > 0 {
+		unary = append(unary, contextHeadersUnaryInterceptor(o.contextHeaders))
+		stream = append(stream, contextHeadersStreamInterceptor(o.contextHeaders))
+	}
+	unary = append(unary, o.unary...)
+	stream = append(stream, o.stream...)
+
+	var opts []grpc.DialOption
+	if len(unary) > 0 {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(unary...))
+	}
+	if len(stream) > 0 {
+		opts = append(opts, grpc.WithChainStreamInterceptor(stream...))
+	}
+	if cfg := o.serviceConfig(); cfg != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(cfg))
+	}
+	if o.maxMsgSize > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(o.maxMsgSize)))
+	}
+	if o.window > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(o.window))
+	}
+	if o.connWindow > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(o.connWindow))
+	}
+	return append(opts, o.dialOptions...)
+}
+
+func timeoutUnaryInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := withDefaultTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// The API-key interceptors send key, or the key set with ContextWithAPIKey
+// on the call's context, in header. No header is sent when both are empty.
+func apiKeyUnaryInterceptor(header, scheme, key string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = withAPIKeyMetadata(ctx, header, scheme, key)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func apiKeyStreamInterceptor(header, scheme, key string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx = withAPIKeyMetadata(ctx, header, scheme, key)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func withAPIKeyMetadata(ctx context.Context, header, scheme, key string) context.Context {
+	if override, ok := ctx.Value(apiKeyContextKey{}).(string); ok {
+		key = override
+	}
+	if key == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, header, authValue(scheme, key))
+}
+
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey returns a context whose GrpcClient calls authenticate
+// with key instead of the key given to WithGrpcAPIKey, so one connection can
+// act for several tenants. It is the gRPC counterpart of WithAPIKey.
+func ContextWithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
@@ -0,0 +1,98 @@
+package barq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/YASSERRMD/barq-db/barq-sdk-go/proto/barq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newBufconnClient serves srv in memory and returns a GrpcClient connected
+// to it. Both are stopped when the test ends.
+func newBufconnClient(t *testing.T, srv pb.BarqServer, opts ...GrpcOption) *GrpcClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterBarqServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+	c, err := NewGrpcClient("bufnet", append([]GrpcOption{WithDialOptions(dialer)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// searchStub answers Search with a fixed response or error.
+type searchStub struct {
+	pb.UnimplementedBarqServer
+	resp *pb.SearchResponse
+	err  error
+}
+
+func (s *searchStub) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	return s.resp, s.err
+}
+
+func TestGrpcSearchNoHitsIsEmptySlice(t *testing.T) {
+	for name, resp := range map[string]*pb.SearchResponse{
+		"empty list": {Results: []*pb.SearchResult{}},
+		"no list":    {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := newBufconnClient(t, &searchStub{resp: resp})
+			results, err := c.Search(context.Background(), "docs", []float32{1, 2}, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results == nil || len(results) != 0 {
+				t.Fatalf("got %#v, want a non-nil empty slice", results)
+			}
+		})
+	}
+}
+
+func TestGrpcSearchErrorHasNoResults(t *testing.T) {
+	c := newBufconnClient(t, &searchStub{err: status.Error(codes.NotFound, "collection docs not found")})
+	results, err := c.Search(context.Background(), "docs", []float32{1, 2}, 5)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got error %v, want one matching ErrNotFound", err)
+	}
+	if results != nil {
+		t.Fatalf("got results %#v alongside an error", results)
+	}
+}
+
+// TestProtoMatchesServer keeps proto/barq.proto identical to the server's
+// definition. It is skipped outside the barq-db repository.
+func TestProtoMatchesServer(t *testing.T) {
+	server, err := os.ReadFile(filepath.Join("..", "barq-proto", "proto", "barq.proto"))
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("server proto not found")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk, err := os.ReadFile(filepath.Join("proto", "barq.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sdk, server) {
+		t.Fatal("proto/barq.proto differs from barq-proto/proto/barq.proto; copy the server's file and regenerate")
+	}
+}
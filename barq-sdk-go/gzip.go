@@ -0,0 +1,103 @@
+package barq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// GzipMode selects when Client compresses request bodies.
+type GzipMode int
+
+const (
+	// GzipOff never compresses. It is the default.
+	GzipOff GzipMode = iota
+	// GzipAlways compresses every body of at least Config.GzipMinBytes.
+	GzipAlways
+	// GzipIfSupported behaves like GzipAlways once ServerInfo reports the
+	// "gzip_requests" feature, and like GzipOff until then. The server is
+	// asked on the first large request.
+	GzipIfSupported
+)
+
+// DefaultGzipMinBytes is the smallest body compressed when
+// Config.GzipMinBytes is zero. Below it, the CPU cost outweighs the savings.
+const DefaultGzipMinBytes = 64 << 10
+
+// featureProbeRetry is how long a capability check that failed is answered
+// from its error before the server is asked again.
+const featureProbeRetry = 30 * time.Second
+
+// featureProbe remembers the answer to a one-off capability check. A server
+// answering 404 or 501 predates the check's endpoint, so it is remembered as
+// lacking the feature. Other failures are remembered for featureProbeRetry,
+// so a struggling server is not probed again by every request.
+type featureProbe struct {
+	mu        sync.Mutex
+	known     bool
+	supported bool
+	err       error
+	failedAt  time.Time
+}
+
+// check returns the remembered answer, running probe if there is none. The
+// error is probe's, or the remembered one while it is fresh.
+func (p *featureProbe) check(probe func() (bool, error)) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.known {
+		return p.supported, nil
+	}
+	if p.err != nil && time.Since(p.failedAt) < featureProbeRetry {
+		return false, p.err
+	}
+	supported, err := probe()
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusNotImplemented) {
+		supported, err = false, nil
+	}
+	if err != nil {
+		p.err, p.failedAt = err, time.Now()
+		return false, err
+	}
+	p.known, p.supported, p.err = true, supported, nil
+	return supported, nil
+}
+
+// compressBody gzips data when Config.Gzip calls for it, reporting whether
+// it did.
+func (c *Client) compressBody(ctx context.Context, data []byte) ([]byte, bool, error) {
+	if c.config.Gzip == GzipOff || len(data) < c.config.GzipMinBytes {
+		return data, false, nil
+	}
+	if c.config.Gzip == GzipIfSupported {
+		// A failed check sends this body uncompressed.
+		supported, _ := c.gzipSupport.check(func() (bool, error) {
+			info, err := c.ServerInfo(ctx)
+			if err != nil {
+				return false, err
+			}
+			return info.HasFeature("gzip_requests"), nil
+		})
+		if !supported {
+			return data, false, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, false, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, false, err
+	}
+	return buf.Bytes(), true, nil
+}
@@ -0,0 +1,121 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFeatureProbeRemembersMissingEndpoint(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusNotImplemented} {
+		var p featureProbe
+		calls := 0
+		probe := func() (bool, error) {
+			calls++
+			return false, &APIError{StatusCode: status}
+		}
+		for i := 0; i < 3; i++ {
+			supported, err := p.check(probe)
+			if supported || err != nil {
+				t.Fatalf("status %d: got (%v, %v), want unsupported without error", status, supported, err)
+			}
+		}
+		if calls != 1 {
+			t.Fatalf("status %d: probed %d times, want 1", status, calls)
+		}
+	}
+}
+
+func TestFeatureProbeRetriesOtherFailuresLater(t *testing.T) {
+	var p featureProbe
+	calls := 0
+	failure := &APIError{StatusCode: http.StatusInternalServerError}
+	probe := func() (bool, error) {
+		calls++
+		if calls == 1 {
+			return false, failure
+		}
+		return true, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.check(probe); !errors.Is(err, failure) {
+			t.Fatalf("got %v, want the remembered failure", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("probed %d times within the retry interval, want 1", calls)
+	}
+
+	p.failedAt = time.Now().Add(-featureProbeRetry)
+	if supported, err := p.check(probe); !supported || err != nil {
+		t.Fatalf("got (%v, %v) after the retry interval, want supported", supported, err)
+	}
+	if calls != 2 {
+		t.Fatalf("probed %d times, want 2", calls)
+	}
+}
+
+func TestGzipIfSupportedProbesOnceOnOldServer(t *testing.T) {
+	var infoCalls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			atomic.AddInt32(&infoCalls, 1)
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Errorf("body sent with Content-Encoding %q to a server without gzip_requests", r.Header.Get("Content-Encoding"))
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(Config{BaseURL: srv.URL, Gzip: GzipIfSupported, GzipMinBytes: 1})
+
+	payload := json.RawMessage(`{"text": "a payload long enough to compress"}`)
+	for i := 0; i < 5; i++ {
+		if err := c.Insert(context.Background(), "docs", InsertRequest{ID: i, Vector: []float32{1, 2}, Payload: payload}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&infoCalls); n != 1 {
+		t.Fatalf("asked /info %d times, want 1", n)
+	}
+}
+
+// BenchmarkCompressBody measures compressing an insert body of 100
+// documents with 768-dimension JSON vectors, reporting the compressed size
+// as a fraction of the original.
+func BenchmarkCompressBody(b *testing.B) {
+	c := NewClient(Config{Gzip: GzipAlways})
+	r := rand.New(rand.NewSource(1))
+	docs := make([]interface{}, 100)
+	for i := range docs {
+		v := make([]float32, 768)
+		for j := range v {
+			v[j] = r.Float32()*2 - 1
+		}
+		docs[i] = c.insertBody(InsertRequest{ID: i, Vector: v})
+	}
+	data, err := json.Marshal(docs)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	var compressed []byte
+	for i := 0; i < b.N; i++ {
+		compressed, _, err = c.compressBody(context.Background(), data)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(len(compressed))/float64(len(data)), "ratio")
+}
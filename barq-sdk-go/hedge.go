@@ -0,0 +1,134 @@
+package barq
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+type hedgePolicy struct {
+	delay       time.Duration
+	maxAttempts int
+}
+
+// WithHedging sends another copy of a read that has not been answered
+// within delay, up to maxAttempts copies in all, and uses whichever answer
+// arrives first; the others are cancelled. It cuts tail latency at the cost
+// of extra load. It applies to searches and other reads only; writes ignore
+// it, as do maxAttempts below 2. Each copy is retried on its own per
+// Config.Retry, and the call's deadline bounds them all.
+func WithHedging(delay time.Duration, maxAttempts int) CallOption {
+	return func(o *callOptions) { o.hedge = &hedgePolicy{delay: delay, maxAttempts: maxAttempts} }
+}
+
+// readCall marks a POST request, such as a search, as a read that may be
+// hedged.
+func readCall() CallOption {
+	return func(o *callOptions) { o.read = true }
+}
+
+func (o *callOptions) hedgeable(method string) bool {
+	if o.hedge == nil || o.hedge.maxAttempts < 2 {
+		return false
+	}
+	return o.read || method == http.MethodGet || method == http.MethodHead
+}
+
+// hedgeLostKey marks the context of a hedged copy; the flag it points to
+// is set once the copy lost, so its cancellation is not counted as an error.
+type hedgeLostKey struct{}
+
+func hedgeLost(ctx context.Context) bool {
+	lost, _ := ctx.Value(hedgeLostKey{}).(*int32)
+	return lost != nil && atomic.LoadInt32(lost) == 1
+}
+
+type hedgeResult struct {
+	leg  int
+	resp *http.Response
+	err  error
+	o    *callOptions
+}
+
+type hedgeLeg struct {
+	cancel context.CancelFunc
+	lost   *int32
+}
+
+// hedged runs attempts against baseURL, hedged per o.hedge when the request
+// allows it.
+func (c *Client) hedged(ctx context.Context, baseURL, method, path string, data []byte, gzipped bool, o *callOptions, start time.Time) (*http.Response, error) {
+	if !o.hedgeable(method) {
+		return c.attempts(ctx, baseURL, method, path, data, gzipped, o, start)
+	}
+
+	results := make(chan hedgeResult, o.hedge.maxAttempts)
+	var legs []hedgeLeg
+	launch := func() {
+		legCtx, cancel := context.WithCancel(ctx)
+		lost := new(int32)
+		legCtx = context.WithValue(legCtx, hedgeLostKey{}, lost)
+		n := len(legs)
+		legs = append(legs, hedgeLeg{cancel: cancel, lost: lost})
+		// Copies must not write the caller's Response concurrently.
+		legOpts := *o
+		if o.response != nil {
+			legOpts.response = &Response{}
+		}
+		go func() {
+			resp, err := c.attempts(legCtx, baseURL, method, path, data, gzipped, &legOpts, start)
+			results <- hedgeResult{leg: n, resp: resp, err: err, o: &legOpts}
+		}()
+	}
+
+	launch()
+	pending := 1
+	timer := time.NewTimer(o.hedge.delay)
+	defer timer.Stop()
+	for {
+		select {
+		case r := <-results:
+			pending--
+			if r.o.response != nil {
+				*o.response = *r.o.response
+			}
+			if r.err != nil {
+				if pending == 0 {
+					for _, leg := range legs {
+						leg.cancel()
+					}
+					return nil, r.err
+				}
+				continue
+			}
+
+			for i, leg := range legs {
+				if i != r.leg {
+					atomic.StoreInt32(leg.lost, 1)
+					leg.cancel()
+				}
+			}
+			go drainHedges(results, pending)
+			r.resp.Body = cancelOnClose{ReadCloser: r.resp.Body, cancel: legs[r.leg].cancel}
+			return r.resp, nil
+
+		case <-timer.C:
+			if len(legs) < o.hedge.maxAttempts {
+				atomic.AddInt64(&c.stats.hedges, 1)
+				launch()
+				pending++
+				timer.Reset(o.hedge.delay)
+			}
+		}
+	}
+}
+
+// drainHedges closes the responses of copies that finish after the winner.
+func drainHedges(results <-chan hedgeResult, n int) {
+	for ; n > 0; n-- {
+		if r := <-results; r.err == nil {
+			r.resp.Body.Close()
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package barq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// ContentID derives a stable document ID from content, so re-ingesting
+// unchanged content maps to the same ID and, with upserts, becomes a no-op.
+//
+// The ID is the lowercase hex SHA-256 of the parts joined by a single NUL
+// byte (0x00), each part encoded as UTF-8; ContentID("a", "b") hashes the
+// three bytes "a\x00b". Other SDKs reproduce it with the same recipe.
+func ContentID(parts ...string) string {
+	h := sha256.New()
+	for i, p := range parts {
+		if i > 0 {
+			h.Write([]byte{0})
+		}
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
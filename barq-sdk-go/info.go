@@ -0,0 +1,48 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ServerInfo is the server's self-description from GET /info.
+type ServerInfo struct {
+	Version     string          `json:"version,omitempty"`
+	Collections int             `json:"collections"`
+	Tenant      string          `json:"tenant"`
+	Usage       ServerUsage     `json:"usage"`
+	Quota       json.RawMessage `json:"quota,omitempty"`
+	// Features lists optional capabilities the server supports, such as
+	// "gzip_requests". Older servers report none.
+	Features []string `json:"features,omitempty"`
+}
+
+// ServerUsage is the calling tenant's resource usage.
+type ServerUsage struct {
+	Documents   int64   `json:"documents"`
+	DiskBytes   int64   `json:"disk_bytes"`
+	MemoryBytes int64   `json:"memory_bytes"`
+	CurrentQPS  float64 `json:"current_qps"`
+}
+
+// HasFeature reports whether the server advertises feature.
+func (info *ServerInfo) HasFeature(feature string) bool {
+	for _, f := range info.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) ServerInfo(ctx context.Context, opts ...CallOption) (*ServerInfo, error) {
+	respBytes, err := c.request(ctx, "GET", "/info", nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var info ServerInfo
+	if err := c.decode(respBytes, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,261 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// SearchIterator walks the results of a search page by page. Use it like
+// sql.Rows:
+//
+//	it := client.SearchIter(ctx, "products", barq.SearchRequest{Vector: v, TopK: 50})
+//	for it.Next() {
+//		r := it.Result()
+//	}
+//	if err := it.Err(); err != nil { ... }
+//
+// The request's TopK is the page size. Pages are followed with the server's
+// continuation token when it returns one. Otherwise each page asks for the
+// results after the last one delivered (SearchRequest.After), so documents
+// inserted or deleted ahead of that point neither repeat nor skip results.
+// Servers that ignore After return the ranking from the top; the iterator
+// then asks for a deeper window each page and drops the results up to the
+// last one delivered. That recomputes earlier pages, so it gets slower the
+// further the iteration goes.
+//
+// The last result delivered marks the spot while it is still in the
+// ranking. Once it is gone, for example deleted, results are placed against
+// its score, with ties broken by ID; if the server orders ties otherwise,
+// results sharing that exact score may be skipped or repeated.
+type SearchIterator struct {
+	ctx        context.Context
+	client     *Client
+	collection string
+	req        SearchRequest
+	opts       []CallOption
+
+	page   []SearchResult
+	pos    int
+	window int  // TopK asked for once the server is known to ignore After
+	tokens bool // the server has returned a continuation token
+	done   bool
+	err    error
+
+	current    SearchResult
+	cursor     SearchCursor
+	positioned bool // cursor is set
+}
+
+// SearchIter returns an iterator over all results of req, starting after
+// req.After when it is set.
+func (c *Client) SearchIter(ctx context.Context, collection string, req SearchRequest, opts ...CallOption) *SearchIterator {
+	it := &SearchIterator{ctx: ctx, client: c, collection: collection, req: req, opts: opts}
+	if req.TopK == 0 {
+		it.req.TopK = c.config.DefaultTopK
+	}
+	if it.req.TopK <= 0 {
+		it.err = errors.New("barq: search iterator needs a positive TopK")
+	}
+	if req.After != nil {
+		it.cursor, it.positioned = *req.After, true
+	}
+	it.req.After = nil
+	return it
+}
+
+// Next advances to the next result, fetching a page when needed. It
+// returns false when the results are exhausted or an error occurred.
+func (it *SearchIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.pos >= len(it.page) {
+		if it.done {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+	it.current = it.page[it.pos]
+	it.pos++
+	it.cursor = SearchCursor{Score: it.current.Score, ID: plainID(it.current.ID)}
+	it.positioned = true
+	return true
+}
+
+func (it *SearchIterator) fetch() error {
+	pageSize := it.req.TopK
+	req := it.req
+	keyset := !it.tokens && it.positioned
+	if keyset {
+		after := it.cursor
+		req.After = &after
+		if it.window > 0 {
+			req.TopK = it.window
+		}
+	}
+
+	resp, err := it.client.searchPage(it.ctx, it.collection, req, it.opts...)
+	if err != nil {
+		return err
+	}
+	results := resp.Results
+	if keyset {
+		results = it.afterCursor(resp.Results)
+		if len(results) < len(resp.Results) {
+			// The server ignored After and ranked from the top.
+			if it.window == 0 {
+				it.window = pageSize
+			}
+			it.window += pageSize
+		}
+	}
+	it.page, it.pos = results, 0
+
+	switch {
+	case resp.NextToken != "":
+		it.tokens = true
+		it.req.PageToken = resp.NextToken
+	case it.tokens, len(resp.Results) < req.TopK:
+		it.done = true
+	}
+	return nil
+}
+
+// afterCursor drops the results ranking at or before the cursor. The
+// cursor's own result marks the spot when the response contains it;
+// otherwise results are compared by similarity, then by ID.
+func (it *SearchIterator) afterCursor(results []SearchResult) []SearchResult {
+	key := idKey(it.cursor.ID)
+	for i, r := range results {
+		if idKey(r.ID) == key {
+			return results[i+1:]
+		}
+	}
+
+	sim := it.client.collectionMetric(it.collection).Similarity(it.cursor.Score)
+	fresh := results[:0:0]
+	for _, r := range results {
+		if r.Similarity < sim || (r.Similarity == sim && idLess(it.cursor.ID, r.ID)) {
+			fresh = append(fresh, r)
+		}
+	}
+	return fresh
+}
+
+// idLess orders IDs for ties: numerically when both are numbers, and by
+// their string form otherwise.
+func idLess(a, b interface{}) bool {
+	ka, kb := idKey(a), idKey(b)
+	fa, errA := strconv.ParseFloat(ka, 64)
+	fb, errB := strconv.ParseFloat(kb, 64)
+	if errA == nil && errB == nil {
+		return fa < fb
+	}
+	return ka < kb
+}
+
+// Result returns the result Next advanced to.
+func (it *SearchIterator) Result() SearchResult {
+	return it.current
+}
+
+// Cursor returns the position after the last result Next delivered, or nil
+// before the first. Set it as SearchRequest.After of a later SearchIter to
+// continue from there, for example on an infinite-scroll page request.
+func (it *SearchIterator) Cursor() *SearchCursor {
+	if !it.positioned {
+		return nil
+	}
+	cursor := it.cursor
+	return &cursor
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// PagedResults is one page of SearchPaged.
+type PagedResults struct {
+	Results  []SearchResult
+	Page     int
+	PageSize int
+	// Total and TotalPages count all matches. They are -1 when the server
+	// does not report a total and the end of the results was not reached.
+	Total      int
+	TotalPages int
+}
+
+// SearchPaged returns page (starting at 1) of req's results, pageSize per
+// page, for UIs that show page numbers. It uses offset paging, asking for
+// the first page*pageSize results, so deep pages cost more. Pages out of
+// range yield empty Results rather than an error.
+func (c *Client) SearchPaged(ctx context.Context, collection string, req SearchRequest, page, pageSize int, opts ...CallOption) (*PagedResults, error) {
+	if pageSize <= 0 {
+		return nil, errors.New("barq: SearchPaged needs a positive page size")
+	}
+	out := &PagedResults{Results: []SearchResult{}, Page: page, PageSize: pageSize, Total: -1, TotalPages: -1}
+	if page < 1 {
+		return out, nil
+	}
+
+	req.TopK = page * pageSize
+	req.PageToken = ""
+	req.After = nil
+	resp, err := c.searchPage(ctx, collection, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.Total > 0:
+		out.Total = resp.Total
+	case len(resp.Results) < req.TopK:
+		out.Total = len(resp.Results)
+	}
+	if out.Total >= 0 {
+		out.TotalPages = (out.Total + pageSize - 1) / pageSize
+	}
+	if start := (page - 1) * pageSize; start < len(resp.Results) {
+		out.Results = resp.Results[start:]
+		if len(out.Results) > pageSize {
+			out.Results = out.Results[:pageSize]
+		}
+	}
+	return out, nil
+}
+
+// searchAllPageSize is the page size SearchAll uses when req.TopK is zero.
+const searchAllPageSize = 100
+
+// SearchAll returns up to limit results of req, fetching as many pages as
+// that takes with SearchIter, so callers need not handle paging. req.TopK
+// sets the page size; zero means 100. It stops at limit, when the results
+// run out, or when ctx ends, and never holds more than limit results. On
+// servers without continuation tokens each page recomputes the ones before
+// it, so a large limit there costs far more than one search of that size.
+func (c *Client) SearchAll(ctx context.Context, collection string, req SearchRequest, limit int, opts ...CallOption) ([]SearchResult, error) {
+	if limit <= 0 {
+		return nil, errors.New("barq: SearchAll needs a positive limit")
+	}
+	if req.TopK == 0 {
+		req.TopK = searchAllPageSize
+	}
+	if req.TopK > limit {
+		req.TopK = limit
+	}
+
+	out := make([]SearchResult, 0, req.TopK)
+	it := c.SearchIter(ctx, collection, req, opts...)
+	for len(out) < limit && it.Next() {
+		out = append(out, it.Result())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,319 @@
+package barq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ExportJSONL writes every document of collection to w as JSON lines, one
+// {"id", "vector", "vectors", "payload"} object per line, and returns how
+// many it wrote. Documents are read page by page, so memory use stays flat
+// and a slow w slows the export down rather than buffering.
+func (c *Client) ExportJSONL(ctx context.Context, collection string, w io.Writer, opts ...CallOption) (int, error) {
+	enc := json.NewEncoder(w)
+	n := 0
+	cursor := ""
+	for {
+		page, err := c.ListDocuments(ctx, collection, cursor, 256, opts...)
+		if err != nil {
+			return n, fmt.Errorf("export %q: %w", collection, err)
+		}
+		for _, doc := range page.Documents {
+			doc.ID = plainID(doc.ID)
+			if err := enc.Encode(doc); err != nil {
+				return n, fmt.Errorf("export %q: %w", collection, err)
+			}
+			n++
+		}
+		if page.NextCursor == "" {
+			return n, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// ImportOption configures ImportJSONL, InsertConcurrent and Migrate.
+type ImportOption func(*importOptions)
+
+type importOptions struct {
+	ConcurrencyOptions
+	concurrency int
+	progress    func(done, total int)
+}
+
+// WithImportConcurrency sets how many inserts run at once. The default is 4.
+func WithImportConcurrency(n int) ImportOption {
+	return func(o *importOptions) { o.concurrency = n }
+}
+
+// WithImportProgress calls fn after each document is handled, successfully
+// or not, with the number handled so far and the total, which is -1 when
+// it is not known up front (ImportJSONL and Migrate read a stream). fn is
+// always called from one goroutine, never concurrently, so it needs no
+// locking of its own, and the last call happens before the import returns.
+// A slow fn slows the import down.
+func WithImportProgress(fn func(done, total int)) ImportOption {
+	return func(o *importOptions) { o.progress = fn }
+}
+
+// DefaultMaxInFlight is the cap ConcurrencyOptions.Adaptive grows the batch
+// size to when MaxInFlight is not set.
+const DefaultMaxInFlight = 64
+
+// ConcurrencyOptions tunes how ImportJSONL, InsertConcurrent and Migrate
+// send their inserts; pass it with WithConcurrencyOptions.
+type ConcurrencyOptions struct {
+	// Adaptive sizes each batch from observed latency and errors instead
+	// of keeping it at WithImportConcurrency, which becomes the starting
+	// size. The HTTP API takes one document per request, so a batch is the
+	// set of inserts in flight at once rather than one request body.
+	//
+	// The control loop is AIMD and works in rounds of as many completed
+	// inserts as the current batch size. A round that saw a failed insert,
+	// or whose mean latency was more than twice the best round mean so far,
+	// halves the size; any other round grows it by one. The best mean is
+	// the baseline for an uncongested server, so batches grow while extra
+	// parallelism is free and shrink quickly once the server queues or
+	// rejects requests. The size never leaves [1, MaxInFlight].
+	Adaptive bool
+	// MaxInFlight caps the adaptive batch size; zero means
+	// DefaultMaxInFlight. It is ignored unless Adaptive is set.
+	MaxInFlight int
+
+	// Dedup drops documents whose vectors and payload duplicate another
+	// document's, counting them in InsertReport.Skipped, or ImportReport's
+	// for stream imports, instead of inserting them. A copy is only dropped
+	// once an insert of the same content has succeeded; if that insert
+	// fails, the copy is inserted in its place. IDs are not compared, so
+	// duplicates emitted upstream under different IDs are caught too.
+	// Payloads are compared byte for byte, so the same JSON with its keys in
+	// another order is not a duplicate.
+	Dedup bool
+	// DedupWindow is where Dedup remembers the documents it has seen. With
+	// none, duplicates are looked for within the one call; share a
+	// DedupWindow between calls to also catch those of recent ones.
+	DedupWindow *DedupWindow
+}
+
+// WithConcurrencyOptions applies opts to an import.
+func WithConcurrencyOptions(opts ConcurrencyOptions) ImportOption {
+	return func(o *importOptions) { o.ConcurrencyOptions = opts }
+}
+
+type importJob struct {
+	pos int // input line, or index into the InsertConcurrent slice
+	req InsertRequest
+}
+
+// ImportJSONL inserts the documents read from r, in the format written by
+// ExportJSONL, upserting each one. Lines are read only as fast as inserts
+// complete, which gives a streaming source natural backpressure.
+//
+// A document that fails to insert is recorded in the report's Failures and
+// the import continues. A read or decode error, or ctx ending, stops the
+// import; the report then covers the documents handled so far.
+func (c *Client) ImportJSONL(ctx context.Context, collection string, r io.Reader, opts ...ImportOption) (*ImportReport, error) {
+	report, err := c.insertAll(ctx, collection, "line", -1, opts, nil, func(jobs chan<- importJob) error {
+		return readJSONL(ctx, r, jobs)
+	})
+	if err != nil {
+		return report, fmt.Errorf("import %q: %w", collection, err)
+	}
+	return report, nil
+}
+
+// InsertConcurrent inserts reqs with a pool of workers (see
+// WithImportConcurrency) and reports the outcome of each document, in
+// request order. A failed insert does not stop the others; only ctx ending
+// does, and documents it kept from being sent are reported as failed with
+// the context's error.
+func (c *Client) InsertConcurrent(ctx context.Context, collection string, reqs []InsertRequest, opts ...ImportOption) (*InsertReport, error) {
+	items := make([]ItemResult, len(reqs))
+	_, err := c.insertAll(ctx, collection, "index", len(reqs), opts, items, func(jobs chan<- importJob) error {
+		for i, req := range reqs {
+			select {
+			case jobs <- importJob{pos: i, req: req}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	for i := range items {
+		if items[i].Status == "" && err != nil {
+			items[i] = ItemResult{ID: reqs[i].ID, Status: ItemFailed, Error: err.Error()}
+		}
+	}
+	return newInsertReport(items), err
+}
+
+// insertAll runs the insert workers over the jobs produced by feed, which
+// must stop when ctx ends. label names what job.pos counts in failure
+// messages, and total is passed through to the progress callback. When
+// items is not nil, the outcome of each job is also stored at its pos.
+func (c *Client) insertAll(ctx context.Context, collection, label string, total int, opts []ImportOption, items []ItemResult, feed func(chan<- importJob) error) (*ImportReport, error) {
+	o := importOptions{concurrency: 4}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.concurrency < 1 {
+		o.concurrency = 1
+	}
+	workers := o.concurrency
+	var limiter *aimdLimiter
+	if o.Adaptive {
+		if o.MaxInFlight < 1 {
+			o.MaxInFlight = DefaultMaxInFlight
+		}
+		workers = o.MaxInFlight
+		limiter = newAIMDLimiter(o.concurrency, o.MaxInFlight)
+	}
+	dedup := o.DedupWindow
+	if o.Dedup && dedup == nil {
+		dedup = NewDedupWindow(0)
+	}
+
+	var progress chan int
+	progressDone := make(chan struct{})
+	if o.progress != nil {
+		progress = make(chan int, workers)
+		go func() {
+			defer close(progressDone)
+			for done := range progress {
+				o.progress(done, total)
+			}
+		}()
+	} else {
+		close(progressDone)
+	}
+
+	report := &ImportReport{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan importJob)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				var key string
+				claimed, err := true, error(nil)
+				if dedup != nil {
+					key = contentHash(collection, job.req)
+					claimed, err = dedup.claim(ctx, key)
+				}
+				skip := !claimed && err == nil
+				if claimed && limiter != nil {
+					err = limiter.acquire(ctx)
+				}
+				if claimed && err == nil {
+					start := time.Now()
+					err = c.Insert(ctx, collection, job.req)
+					if limiter != nil {
+						limiter.release(time.Since(start), err)
+					}
+				}
+				if claimed && dedup != nil {
+					dedup.finish(key, err == nil)
+				}
+				item := ItemResult{ID: job.req.ID, Status: ItemOK}
+				mu.Lock()
+				switch {
+				case skip:
+					item.Status = ItemSkipped
+					report.Skipped++
+				case err != nil:
+					item.Status, item.Error = ItemFailed, err.Error()
+					report.Failed++
+					report.Failures = append(report.Failures, ItemResult{
+						ID:     job.req.ID,
+						Status: ItemFailed,
+						Error:  fmt.Sprintf("%s %d: %v", label, job.pos, err),
+					})
+				default:
+					report.Imported++
+				}
+				if items != nil {
+					items[job.pos] = item
+				}
+				if progress != nil {
+					// Sent under mu so counts arrive in order.
+					progress <- report.Imported + report.Failed + report.Skipped
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := feed(jobs)
+	close(jobs)
+	wg.Wait()
+	if progress != nil {
+		close(progress)
+	}
+	<-progressDone
+	return report, err
+}
+
+func readJSONL(ctx context.Context, r io.Reader, jobs chan<- importJob) error {
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			var doc Document
+			if jsonErr := json.Unmarshal(data, &doc); jsonErr != nil {
+				return fmt.Errorf("line %d: %w", line, jsonErr)
+			}
+			if opaqueErr := decodeOpaque(&doc); opaqueErr != nil {
+				return fmt.Errorf("line %d: %w", line, opaqueErr)
+			}
+			job := importJob{pos: line, req: InsertRequest{
+				ID:                 doc.ID,
+				Vector:             doc.Vector,
+				Vectors:            doc.Vectors,
+				Payload:            doc.Payload,
+				PayloadContentType: doc.PayloadContentType,
+				Upsert:             true,
+			}}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// Migrate streams every document of srcCol on src into dstCol on dst,
+// connecting ExportJSONL to ImportJSONL through an io.Pipe so nothing is
+// buffered to disk. The destination collection must exist. The export only
+// runs as fast as the import consumes it. Per-document failures are
+// reported as in ImportJSONL; an export error aborts the import and is
+// returned.
+func Migrate(ctx context.Context, src *Client, srcCol string, dst *Client, dstCol string, opts ...ImportOption) (*ImportReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := src.ExportJSONL(ctx, srcCol, pw)
+		pw.CloseWithError(err)
+	}()
+
+	report, err := dst.ImportJSONL(ctx, dstCol, pr, opts...)
+	// Unblock the exporter if the import stopped first.
+	pr.CloseWithError(io.ErrClosedPipe)
+	return report, err
+}
@@ -0,0 +1,153 @@
+package barq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+type Metric string
+
+const (
+	MetricL2     Metric = "L2"
+	MetricCosine Metric = "Cosine"
+	MetricDot    Metric = "Dot"
+)
+
+// Similarity converts a raw score returned for this metric into a value
+// where higher always means more similar:
+//
+//	L2:     1 / (1 + d), where d = |score| is the Euclidean distance
+//	        (the server reports L2 as a negated distance).
+//	Cosine: score unchanged (cosine similarity, in [-1, 1]).
+//	Dot:    score unchanged (inner product).
+//
+// Unknown metrics return the score unchanged.
+func (m Metric) Similarity(score float32) float32 {
+	if m == MetricL2 {
+		if score < 0 {
+			score = -score
+		}
+		return 1 / (1 + score)
+	}
+	return score
+}
+
+// Better reports whether score a ranks ahead of score b under metric. It
+// compares Similarity values, so an L2 score counts as better the closer it
+// is to zero whether it is given as a distance or, as the server reports it,
+// a negated distance.
+func Better(metric Metric, a, b float32) bool {
+	return metric.Similarity(a) > metric.Similarity(b)
+}
+
+// SortResults orders results best first under metric, keeping the relative
+// order of equal scores.
+func SortResults(metric Metric, results []SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return Better(metric, results[i].Score, results[j].Score)
+	})
+}
+
+// CompareResults reports the first difference between two result lists for
+// the same search, for example one run over HTTP and one over gRPC: a
+// different length, a different ID at some rank (IDs are compared by value,
+// so 7 and "7" match), scores further apart than tolerance, or different
+// payloads. Payloads are compared as JSON values, so key order and spacing
+// do not matter, and a missing payload matches null and {}, which servers
+// send for a document stored without one. It returns nil when the lists
+// agree.
+func CompareResults(want, got []SearchResult, tolerance float32) error {
+	if len(want) != len(got) {
+		return fmt.Errorf("got %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if idKey(want[i].ID) != idKey(got[i].ID) {
+			return fmt.Errorf("result %d: got id %v, want %v", i, got[i].ID, want[i].ID)
+		}
+		if d := want[i].Score - got[i].Score; d > tolerance || d < -tolerance {
+			return fmt.Errorf("result %d (id %v): got score %g, want %g", i, got[i].ID, got[i].Score, want[i].Score)
+		}
+		if !samePayload(want[i].Payload, got[i].Payload) {
+			return fmt.Errorf("result %d (id %v): got payload %s, want %s", i, got[i].ID, got[i].Payload, want[i].Payload)
+		}
+	}
+	return nil
+}
+
+func samePayload(a, b json.RawMessage) bool {
+	va, errA := payloadValue(a)
+	vb, errB := payloadValue(b)
+	if errA != nil || errB != nil {
+		return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// payloadValue decodes a payload for comparison, with an empty, null or {}
+// payload as nil.
+func payloadValue(p json.RawMessage) (interface{}, error) {
+	if len(bytes.TrimSpace(p)) == 0 {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(p, &v); err != nil {
+		return nil, err
+	}
+	if m, ok := v.(map[string]interface{}); ok && len(m) == 0 {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// metricCache remembers the metric of collections this client has created
+// or described, so results can be normalized without a lookup per call.
+type metricCache struct {
+	m sync.Map
+}
+
+func (c *metricCache) get(collection string) (Metric, bool) {
+	v, ok := c.m.Load(collection)
+	if !ok {
+		return "", false
+	}
+	return v.(Metric), true
+}
+
+func (c *metricCache) set(collection string, metric Metric) {
+	c.m.Store(collection, metric)
+}
+
+func setSimilarity(results []SearchResult, metric Metric) {
+	for i := range results {
+		results[i].Similarity = metric.Similarity(results[i].Score)
+	}
+}
+
+// normalizeScores sets NormScore by min-max scaling Similarity across
+// results: the best hit gets 1 and the worst 0. When all hits are equally
+// similar, including a single hit, every NormScore is 1.
+func normalizeScores(results []SearchResult) {
+	if len(results) == 0 {
+		return
+	}
+	lo, hi := results[0].Similarity, results[0].Similarity
+	for _, r := range results[1:] {
+		if r.Similarity < lo {
+			lo = r.Similarity
+		}
+		if r.Similarity > hi {
+			hi = r.Similarity
+		}
+	}
+	for i := range results {
+		if hi == lo {
+			results[i].NormScore = 1
+		} else {
+			results[i].NormScore = (results[i].Similarity - lo) / (hi - lo)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package barq
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultCollectionNamePattern is the collection name rule used when
+// Config.CollectionNamePattern or WithGrpcCollectionNamePattern is not set.
+// A name is 1 to 128 ASCII letters, digits, dots, underscores and hyphens,
+// starting with a letter or digit. Spaces, slashes and other characters that
+// would need escaping in a request path are rejected before anything is sent.
+var DefaultCollectionNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+func (c *Client) checkCollectionName(name string) error {
+	return checkCollectionName(c.config.CollectionNamePattern, name)
+}
+
+// checkCollectionName reports names that do not match pattern, or
+// DefaultCollectionNamePattern when it is nil, before a request is sent.
+func checkCollectionName(pattern *regexp.Regexp, name string) error {
+	if pattern == nil {
+		pattern = DefaultCollectionNamePattern
+	}
+	if !pattern.MatchString(name) {
+		return fmt.Errorf("%w %q: must match %s", ErrInvalidCollectionName, name, pattern)
+	}
+	return nil
+}
+
+// collectionPath is the path of a collection resource, with the name
+// escaped so a name the pattern lets through cannot change the route. elems
+// are appended as is.
+func collectionPath(name string, elems ...string) string {
+	path := "/collections/" + url.PathEscape(name)
+	if len(elems) > 0 {
+		path += "/" + strings.Join(elems, "/")
+	}
+	return path
+}
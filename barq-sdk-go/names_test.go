@@ -0,0 +1,31 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCollectionNamePattern(t *testing.T) {
+	for _, name := range []string{"docs", "docs.v2", "my_docs-2", "0", strings.Repeat("a", 128)} {
+		if err := checkCollectionName(nil, name); err != nil {
+			t.Errorf("%q: got %v, want the name to pass", name, err)
+		}
+	}
+	for _, name := range []string{"", " ", "\t\n", "  "} {
+		if err := checkCollectionName(nil, name); !errors.Is(err, ErrInvalidCollectionName) {
+			t.Errorf("%q: got %v, want ErrInvalidCollectionName", name, err)
+		}
+	}
+}
+
+func TestGrpcClientChecksCollectionName(t *testing.T) {
+	c := newBufconnClient(t, &searchStub{err: errors.New("should not be called")})
+	if _, err := c.Search(context.Background(), " ", []float32{1}, 1); !errors.Is(err, ErrInvalidCollectionName) {
+		t.Fatalf("Search: got %v, want ErrInvalidCollectionName", err)
+	}
+	if err := c.CreateCollection(context.Background(), "", 3, "L2"); !errors.Is(err, ErrInvalidCollectionName) {
+		t.Fatalf("CreateCollection: got %v, want ErrInvalidCollectionName", err)
+	}
+}
@@ -0,0 +1,74 @@
+package barq
+
+import "net/http"
+
+// CallOption adjusts a single Client call without changing the client's
+// shared configuration.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	idempotencyKey string
+	// idempotent marks a request as safe to replay without a key.
+	idempotent bool
+	apiKey     *string
+	softDelete bool
+	response   *Response
+	hedge      *hedgePolicy
+	// read marks a POST that only reads, such as a search.
+	read bool
+}
+
+func newCallOptions(opts []CallOption) *callOptions {
+	o := &callOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithIdempotencyKey sends key in the Idempotency-Key header, telling the
+// server to apply the request at most once. Requests carrying a key are safe
+// to replay, so the retry layer also retries them after network errors.
+func WithIdempotencyKey(key string) CallOption {
+	return func(o *callOptions) { o.idempotencyKey = key }
+}
+
+// WithAPIKey authenticates the call with key instead of Config.APIKey, for
+// tools that act as several tenants through one client. The header name and
+// scheme still come from the Config. GrpcClient calls take the key from
+// their context instead; see ContextWithAPIKey.
+func WithAPIKey(key string) CallOption {
+	return func(o *callOptions) { o.apiKey = &key }
+}
+
+func idempotent() CallOption {
+	return func(o *callOptions) { o.idempotent = true }
+}
+
+// Response describes the HTTP response to a call made with WithResponse.
+type Response struct {
+	StatusCode int
+	Headers    http.Header
+	// RequestID is the server's X-Request-Id header, if any.
+	RequestID string
+}
+
+// WithResponse fills r with the status and headers of the HTTP response once
+// the call returns. It is honored by every Client method; for methods that
+// issue several requests (such as SearchByID) or retried calls, r describes
+// the last response received. r is also filled when the server answers with
+// an error status.
+func WithResponse(r *Response) CallOption {
+	return func(o *callOptions) { o.response = r }
+}
+
+func (o *callOptions) recordResponse(resp *http.Response) {
+	if o.response == nil {
+		return
+	}
+	*o.response = Response{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+}
@@ -0,0 +1,118 @@
+package barq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+)
+
+// opaquePayload reports whether a payload content type is anything other
+// than JSON, so the payload is sent as bytes rather than embedded JSON.
+func opaquePayload(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err != nil || mediaType != "application/json"
+}
+
+// documentJSON is Document without its JSON methods.
+type documentJSON Document
+
+// MarshalJSON encodes an opaque payload as a base64 string, the form the
+// server returns it in.
+func (d Document) MarshalJSON() ([]byte, error) {
+	if !opaquePayload(d.PayloadContentType) || d.Payload == nil {
+		return json.Marshal(documentJSON(d))
+	}
+	return json.Marshal(struct {
+		documentJSON
+		Payload []byte `json:"payload"`
+	}{documentJSON(d), d.Payload})
+}
+
+// decodeOpaque turns a decoded document's opaque payload from its base64
+// string back into the bytes that were inserted. It is a separate step
+// rather than an UnmarshalJSON method so StrictDecode still reaches the
+// document's fields.
+func decodeOpaque(doc *Document) error {
+	if !opaquePayload(doc.PayloadContentType) || !present(doc.Payload) {
+		return nil
+	}
+	var raw []byte
+	if err := json.Unmarshal(doc.Payload, &raw); err != nil {
+		return fmt.Errorf("barq: %s payload of document %v: %w", doc.PayloadContentType, doc.ID, err)
+	}
+	doc.Payload = raw
+	return nil
+}
+
+// PayloadOptions controls MarshalPayload.
+type PayloadOptions struct {
+	// OmitEmpty drops object fields holding a zero value (null, false, 0,
+	// "", [] or {}) at every level, as if each field were tagged
+	// omitempty. Some filters treat a missing field differently from an
+	// empty one, so choose deliberately.
+	OmitEmpty bool
+}
+
+// MarshalPayload encodes v for InsertRequest.Payload. By default v is
+// encoded exactly as encoding/json would, honoring its struct tags and any
+// json.Marshaler it implements, so zero values are included unless tagged
+// omitempty. Set OmitEmpty to drop them without changing the struct.
+func MarshalPayload(v interface{}, opts PayloadOptions) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil || !opts.OmitEmpty {
+		return data, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(dropEmpty(generic))
+}
+
+// dropEmpty removes empty-valued fields from objects, recursing into
+// objects and arrays. Array elements themselves are never removed.
+func dropEmpty(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			field = dropEmpty(field)
+			if isEmptyJSON(field) {
+				delete(v, k)
+			} else {
+				v[k] = field
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = dropEmpty(v[i])
+		}
+	}
+	return v
+}
+
+func isEmptyJSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case string:
+		return v == ""
+	case json.Number:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		return err == nil && f == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package barq
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ContextHeader names a context value to forward with every request, such
+// as a tenant ID or tracing baggage stored by middleware. Key is the key
+// the value was stored under with context.WithValue, and Header the HTTP
+// header or gRPC metadata key it is sent as. Strings and fmt.Stringers are
+// sent as they are, other values formatted with fmt.Sprint; a missing or
+// nil value sends nothing.
+type ContextHeader struct {
+	Key    interface{}
+	Header string
+}
+
+func (h ContextHeader) value(ctx context.Context) (string, bool) {
+	switch v := ctx.Value(h.Key).(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case fmt.Stringer:
+		return v.String(), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// setContextHeaders copies the configured context values to req.
+func setContextHeaders(ctx context.Context, req *http.Request, headers []ContextHeader) {
+	for _, h := range headers {
+		if v, ok := h.value(ctx); ok {
+			req.Header.Set(h.Header, v)
+		}
+	}
+}
+
+// WithGrpcContextHeaders forwards the given context values as metadata on
+// every call, the gRPC counterpart of Config.ContextHeaders. Header names
+// are lowercased, as gRPC requires.
+func WithGrpcContextHeaders(headers ...ContextHeader) GrpcOption {
+	return func(o *grpcOptions) { o.contextHeaders = append(o.contextHeaders, headers...) }
+}
+
+func withContextMetadata(ctx context.Context, headers []ContextHeader) context.Context {
+	var kv []string
+	for _, h := range headers {
+		if v, ok := h.value(ctx); ok {
+			kv = append(kv, strings.ToLower(h.Header), v)
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+func contextHeadersUnaryInterceptor(headers []ContextHeader) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withContextMetadata(ctx, headers), method, req, reply, cc, opts...)
+	}
+}
+
+func contextHeadersStreamInterceptor(headers []ContextHeader) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withContextMetadata(ctx, headers), desc, cc, method, opts...)
+	}
+}
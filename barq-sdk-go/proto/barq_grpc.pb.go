@@ -25,7 +25,12 @@ type BarqClient interface {
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	CreateCollection(ctx context.Context, in *CreateCollectionRequest, opts ...grpc.CallOption) (*CreateCollectionResponse, error)
 	InsertDocument(ctx context.Context, in *InsertDocumentRequest, opts ...grpc.CallOption) (*InsertDocumentResponse, error)
+	BatchInsert(ctx context.Context, in *BatchInsertRequest, opts ...grpc.CallOption) (*BatchInsertResponse, error)
 	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	BatchDelete(ctx context.Context, opts ...grpc.CallOption) (Barq_BatchDeleteClient, error)
+	GetDocument(ctx context.Context, in *GetDocumentRequest, opts ...grpc.CallOption) (*GetDocumentResponse, error)
+	ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error)
+	BatchSearch(ctx context.Context, in *BatchSearchRequest, opts ...grpc.CallOption) (*BatchSearchResponse, error)
 }
 
 type barqClient struct {
@@ -63,6 +68,15 @@ func (c *barqClient) InsertDocument(ctx context.Context, in *InsertDocumentReque
 	return out, nil
 }
 
+func (c *barqClient) BatchInsert(ctx context.Context, in *BatchInsertRequest, opts ...grpc.CallOption) (*BatchInsertResponse, error) {
+	out := new(BatchInsertResponse)
+	err := c.cc.Invoke(ctx, "/barq.Barq/BatchInsert", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *barqClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
 	out := new(SearchResponse)
 	err := c.cc.Invoke(ctx, "/barq.Barq/Search", in, out, opts...)
@@ -72,6 +86,67 @@ func (c *barqClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc
 	return out, nil
 }
 
+func (c *barqClient) BatchDelete(ctx context.Context, opts ...grpc.CallOption) (Barq_BatchDeleteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Barq_ServiceDesc.Streams[0], "/barq.Barq/BatchDelete", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &barqBatchDeleteClient{stream}
+	return x, nil
+}
+
+type Barq_BatchDeleteClient interface {
+	Send(*DeleteDocumentRequest) error
+	CloseAndRecv() (*BatchDeleteResponse, error)
+	grpc.ClientStream
+}
+
+type barqBatchDeleteClient struct {
+	grpc.ClientStream
+}
+
+func (x *barqBatchDeleteClient) Send(m *DeleteDocumentRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *barqBatchDeleteClient) CloseAndRecv() (*BatchDeleteResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BatchDeleteResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *barqClient) GetDocument(ctx context.Context, in *GetDocumentRequest, opts ...grpc.CallOption) (*GetDocumentResponse, error) {
+	out := new(GetDocumentResponse)
+	err := c.cc.Invoke(ctx, "/barq.Barq/GetDocument", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *barqClient) ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error) {
+	out := new(ListCollectionsResponse)
+	err := c.cc.Invoke(ctx, "/barq.Barq/ListCollections", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *barqClient) BatchSearch(ctx context.Context, in *BatchSearchRequest, opts ...grpc.CallOption) (*BatchSearchResponse, error) {
+	out := new(BatchSearchResponse)
+	err := c.cc.Invoke(ctx, "/barq.Barq/BatchSearch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BarqServer is the server API for Barq service.
 // All implementations must embed UnimplementedBarqServer
 // for forward compatibility
@@ -79,7 +154,12 @@ type BarqServer interface {
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	CreateCollection(context.Context, *CreateCollectionRequest) (*CreateCollectionResponse, error)
 	InsertDocument(context.Context, *InsertDocumentRequest) (*InsertDocumentResponse, error)
+	BatchInsert(context.Context, *BatchInsertRequest) (*BatchInsertResponse, error)
 	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	BatchDelete(Barq_BatchDeleteServer) error
+	GetDocument(context.Context, *GetDocumentRequest) (*GetDocumentResponse, error)
+	ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error)
+	BatchSearch(context.Context, *BatchSearchRequest) (*BatchSearchResponse, error)
 	mustEmbedUnimplementedBarqServer()
 }
 
@@ -96,9 +176,24 @@ func (UnimplementedBarqServer) CreateCollection(context.Context, *CreateCollecti
 func (UnimplementedBarqServer) InsertDocument(context.Context, *InsertDocumentRequest) (*InsertDocumentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InsertDocument not implemented")
 }
+func (UnimplementedBarqServer) BatchInsert(context.Context, *BatchInsertRequest) (*BatchInsertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchInsert not implemented")
+}
 func (UnimplementedBarqServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
 }
+func (UnimplementedBarqServer) BatchDelete(Barq_BatchDeleteServer) error {
+	return status.Errorf(codes.Unimplemented, "method BatchDelete not implemented")
+}
+func (UnimplementedBarqServer) GetDocument(context.Context, *GetDocumentRequest) (*GetDocumentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDocument not implemented")
+}
+func (UnimplementedBarqServer) ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollections not implemented")
+}
+func (UnimplementedBarqServer) BatchSearch(context.Context, *BatchSearchRequest) (*BatchSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchSearch not implemented")
+}
 func (UnimplementedBarqServer) mustEmbedUnimplementedBarqServer() {}
 
 // UnsafeBarqServer may be embedded to opt out of forward compatibility for this service.
@@ -166,6 +261,24 @@ func _Barq_InsertDocument_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Barq_BatchInsert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchInsertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BarqServer).BatchInsert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/barq.Barq/BatchInsert",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BarqServer).BatchInsert(ctx, req.(*BatchInsertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Barq_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SearchRequest)
 	if err := dec(in); err != nil {
@@ -184,6 +297,86 @@ func _Barq_Search_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Barq_BatchDelete_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BarqServer).BatchDelete(&barqBatchDeleteServer{stream})
+}
+
+type Barq_BatchDeleteServer interface {
+	SendAndClose(*BatchDeleteResponse) error
+	Recv() (*DeleteDocumentRequest, error)
+	grpc.ServerStream
+}
+
+type barqBatchDeleteServer struct {
+	grpc.ServerStream
+}
+
+func (x *barqBatchDeleteServer) SendAndClose(m *BatchDeleteResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *barqBatchDeleteServer) Recv() (*DeleteDocumentRequest, error) {
+	m := new(DeleteDocumentRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Barq_GetDocument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDocumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BarqServer).GetDocument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/barq.Barq/GetDocument",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BarqServer).GetDocument(ctx, req.(*GetDocumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Barq_ListCollections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCollectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BarqServer).ListCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/barq.Barq/ListCollections",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BarqServer).ListCollections(ctx, req.(*ListCollectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Barq_BatchSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BarqServer).BatchSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/barq.Barq/BatchSearch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BarqServer).BatchSearch(ctx, req.(*BatchSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Barq_ServiceDesc is the grpc.ServiceDesc for Barq service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -203,11 +396,33 @@ var Barq_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "InsertDocument",
 			Handler:    _Barq_InsertDocument_Handler,
 		},
+		{
+			MethodName: "BatchInsert",
+			Handler:    _Barq_BatchInsert_Handler,
+		},
 		{
 			MethodName: "Search",
 			Handler:    _Barq_Search_Handler,
 		},
+		{
+			MethodName: "GetDocument",
+			Handler:    _Barq_GetDocument_Handler,
+		},
+		{
+			MethodName: "ListCollections",
+			Handler:    _Barq_ListCollections_Handler,
+		},
+		{
+			MethodName: "BatchSearch",
+			Handler:    _Barq_BatchSearch_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchDelete",
+			Handler:       _Barq_BatchDelete_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "barq-sdk-go/proto/barq.proto",
 }
@@ -0,0 +1,44 @@
+package barq
+
+import (
+	"math"
+	"math/rand"
+)
+
+// RandomVector returns a vector of dim components drawn uniformly from
+// [-1, 1), for tests and load scripts. Pass a seeded rng for reproducible
+// vectors; nil uses the math/rand global source.
+func RandomVector(dim int, rng *rand.Rand) []float32 {
+	float := rand.Float32
+	if rng != nil {
+		float = rng.Float32
+	}
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = 2*float() - 1
+	}
+	return v
+}
+
+// RandomUnitVector is RandomVector scaled to unit length, as cosine
+// collections expect.
+func RandomUnitVector(dim int, rng *rand.Rand) []float32 {
+	if dim <= 0 {
+		return make([]float32, 0)
+	}
+	for {
+		v := RandomVector(dim, rng)
+		var sum float64
+		for _, f := range v {
+			sum += float64(f) * float64(f)
+		}
+		if sum == 0 {
+			continue
+		}
+		norm := float32(math.Sqrt(sum))
+		for i := range v {
+			v[i] /= norm
+		}
+		return v
+	}
+}
@@ -0,0 +1,53 @@
+package barq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// SetCollectionReadOnly turns write protection of a collection on or off.
+// While it is on, the server rejects inserts, updates and deletes with an
+// error matching ErrReadOnly; searches are unaffected. It needs a server
+// exposing PUT /collections/{name}/read_only.
+func (c *Client) SetCollectionReadOnly(ctx context.Context, name string, readOnly bool, opts ...CallOption) error {
+	if err := c.checkCollectionName(name); err != nil {
+		return err
+	}
+	path := collectionPath(name, "read_only")
+	respBytes, err := c.request(ctx, "PUT", path, map[string]bool{"read_only": readOnly}, opts...)
+	if err != nil {
+		return err
+	}
+
+	// Servers may answer with the updated collection; otherwise patch
+	// whatever is cached.
+	if len(bytes.TrimSpace(respBytes)) > 0 {
+		var info CollectionInfo
+		if err := c.decode(respBytes, &info); err != nil {
+			return err
+		}
+		if info.Name != "" {
+			c.meta.set(&info)
+			return nil
+		}
+	}
+	if cached, ok := c.meta.get(name); ok {
+		info := *cached
+		info.ReadOnly = readOnly
+		c.meta.set(&info)
+	}
+	return nil
+}
+
+// checkWritable fails writes to collections known to be read-only when
+// Config.EnforceReadOnly is set.
+func (c *Client) checkWritable(collection string) error {
+	if !c.config.EnforceReadOnly {
+		return nil
+	}
+	if info, ok := c.meta.get(collection); ok && info.ReadOnly {
+		return fmt.Errorf("collection %q: %w", collection, ErrReadOnly)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package barq
+
+import (
+	"context"
+	"fmt"
+)
+
+// ImportReport summarizes a bulk copy such as Reindex or ImportJSONL.
+type ImportReport struct {
+	// Imported counts the documents written to the destination.
+	Imported int
+	// Failed counts documents that could not be written, and Failures
+	// describes each of them. Reindex stops at the first failure instead.
+	Failed   int
+	Failures []ItemResult
+	// Skipped counts documents left out on purpose: those dropped by
+	// ConcurrencyOptions.Dedup as duplicates of one that was written, and
+	// the soft-deleted documents Reindex does not copy.
+	Skipped int
+	// Cursor is the checkpoint after the last page that was copied in
+	// full. Pass it to ResumeFrom to continue an interrupted run; it is
+	// empty once the source is exhausted.
+	Cursor string
+}
+
+// ReindexOption configures Reindex.
+type ReindexOption func(*reindexOptions)
+
+type reindexOptions struct {
+	pageSize int
+	resume   string
+	progress func(ImportReport)
+}
+
+// WithReindexPageSize sets how many documents are read from the source per
+// page. The default is 256.
+func WithReindexPageSize(n int) ReindexOption {
+	return func(o *reindexOptions) { o.pageSize = n }
+}
+
+// ResumeFrom continues a Reindex from a checkpoint cursor taken from an
+// earlier ImportReport. The destination is assumed to exist already.
+func ResumeFrom(cursor string) ReindexOption {
+	return func(o *reindexOptions) { o.resume = cursor }
+}
+
+// WithReindexProgress calls fn after each copied page with the running
+// report, whose Cursor is the checkpoint to resume from.
+func WithReindexProgress(fn func(ImportReport)) ReindexOption {
+	return func(o *reindexOptions) { o.progress = fn }
+}
+
+// Reindex copies every live document of source into a new collection dest
+// created from newConfig, e.g. to change index parameters. The Name of
+// newConfig is set to dest; every setting it leaves unset (a zero Dimension
+// or empty Metric, a nil Index, TextFields, PayloadFields or Vectors) is
+// taken from source, so the copy keeps the source's schema and index unless
+// overridden.
+//
+// Documents are read with ListDocuments and upserted one by one, so
+// resuming from a checkpoint may safely rewrite part of a page.
+// Soft-deleted documents are not copied, since they would be live in dest;
+// they are counted in the report's Skipped. On error the returned report
+// holds the progress so far alongside the error.
+func (c *Client) Reindex(ctx context.Context, source, dest string, newConfig CreateCollectionRequest, opts ...ReindexOption) (*ImportReport, error) {
+	o := reindexOptions{pageSize: 256}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	report := &ImportReport{Cursor: o.resume}
+
+	if o.resume == "" {
+		info, err := c.collectionInfo(ctx, source)
+		if err != nil {
+			return report, fmt.Errorf("reindex: describe %q: %w", source, err)
+		}
+		newConfig = inheritConfig(newConfig, info)
+		newConfig.Name = dest
+		if err := c.CreateCollection(ctx, newConfig); err != nil {
+			return report, fmt.Errorf("reindex: create %q: %w", dest, err)
+		}
+	}
+
+	cursor := o.resume
+	for {
+		page, err := c.ListDocuments(ctx, source, cursor, o.pageSize)
+		if err != nil {
+			return report, fmt.Errorf("reindex: list %q: %w", source, err)
+		}
+		for _, doc := range page.Documents {
+			if doc.DeletedAt != nil {
+				report.Skipped++
+				continue
+			}
+			err := c.Insert(ctx, dest, InsertRequest{
+				ID:                 plainID(doc.ID),
+				Vector:             doc.Vector,
+				Vectors:            doc.Vectors,
+				Payload:            doc.Payload,
+				PayloadContentType: doc.PayloadContentType,
+				Upsert:             true,
+			})
+			if err != nil {
+				return report, fmt.Errorf("reindex: insert %v into %q: %w", doc.ID, dest, err)
+			}
+			report.Imported++
+		}
+
+		cursor = page.NextCursor
+		report.Cursor = cursor
+		if o.progress != nil {
+			o.progress(*report)
+		}
+		if cursor == "" {
+			return report, nil
+		}
+	}
+}
+
+// inheritConfig fills the settings config leaves unset from the source
+// collection's info.
+func inheritConfig(config CreateCollectionRequest, info *CollectionInfo) CreateCollectionRequest {
+	if config.Dimension == 0 {
+		config.Dimension = info.Dimension
+	}
+	if config.Metric == "" {
+		config.Metric = string(info.Metric)
+	}
+	if config.Index == nil {
+		config.Index = info.Index
+	}
+	if config.TextFields == nil {
+		config.TextFields = info.TextFields
+	}
+	if config.PayloadFields == nil {
+		config.PayloadFields = info.PayloadFields
+	}
+	if config.Vectors == nil {
+		config.Vectors = info.Vectors
+	}
+	return config
+}
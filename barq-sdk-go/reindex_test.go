@@ -0,0 +1,72 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReindexInheritsSchemaAndSkipsDeleted(t *testing.T) {
+	var mu sync.Mutex
+	var created map[string]json.RawMessage
+	var inserted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/collections/src":
+			w.Write([]byte(`{"name": "src", "dimension": 2, "metric": "Cosine",
+				"index": {"type": "hnsw", "m": 16},
+				"text_fields": [{"name": "body", "indexed": true, "required": true}],
+				"payload_fields": [{"name": "year", "type": "int", "indexed": true}],
+				"vectors": [{"name": "title", "dimension": 2}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/collections":
+			json.NewDecoder(r.Body).Decode(&created)
+		case r.Method == http.MethodGet && r.URL.Path == "/collections/src/documents":
+			w.Write([]byte(`{"documents": [
+				{"id": 1, "vector": [1, 0]},
+				{"id": 2, "vector": [0, 1], "deleted_at": "2026-01-02T03:04:05Z"},
+				{"id": 3, "vector": [1, 1]}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/collections/dst/documents":
+			var doc struct{ ID json.RawMessage }
+			json.NewDecoder(r.Body).Decode(&doc)
+			inserted = append(inserted, string(doc.ID))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(Config{BaseURL: srv.URL})
+
+	report, err := c.Reindex(context.Background(), "src", "dst", CreateCollectionRequest{
+		Index: map[string]interface{}{"type": "hnsw", "m": 32},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"name":           `"dst"`,
+		"dimension":      `2`,
+		"metric":         `"Cosine"`,
+		"index":          `{"m":32,"type":"hnsw"}`,
+		"text_fields":    `[{"name":"body","indexed":true,"required":true}]`,
+		"payload_fields": `[{"name":"year","type":"int","indexed":true}]`,
+		"vectors":        `[{"name":"title","dimension":2}]`,
+	}
+	for key, w := range want {
+		if got := string(created[key]); got != w {
+			t.Errorf("created %s = %s, want %s", key, got, w)
+		}
+	}
+	if got := strings.Join(inserted, ","); got != "1,3" {
+		t.Errorf("inserted %s, want only the live documents 1,3", got)
+	}
+	if report.Imported != 2 || report.Skipped != 1 {
+		t.Errorf("got %+v, want 2 imported and the soft-deleted one skipped", report)
+	}
+}
@@ -0,0 +1,103 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tricklingServer answers with a 200 and writes prefix, then one byte of
+// filler every interval until the client goes away, without ever finishing
+// the body. A zero interval stalls after the prefix instead. When
+// contentLength is positive it is sent as the Content-Length; otherwise the
+// response is chunked.
+func tricklingServer(t *testing.T, prefix string, interval time.Duration, contentLength int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentLength > 0 {
+			w.Header().Set("Content-Length", strconv.Itoa(contentLength))
+		}
+		w.Write([]byte(prefix))
+		w.(http.Flusher).Flush()
+		if interval == 0 {
+			<-r.Context().Done()
+			return
+		}
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-tick.C:
+				w.Write([]byte(" "))
+				w.(http.Flusher).Flush()
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStalledBodyFailsAtDeadline(t *testing.T) {
+	for name, interval := range map[string]time.Duration{
+		"stalled":   0,
+		"trickling": 5 * time.Millisecond,
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := tricklingServer(t, `{"results": [`, interval, 0)
+			c := NewClient(Config{BaseURL: srv.URL, Timeout: 100 * time.Millisecond})
+
+			start := time.Now()
+			results, err := c.Search(context.Background(), "docs", SearchRequest{Vector: []float32{1, 2}, TopK: 5})
+			if err == nil || !strings.Contains(err.Error(), "reading response") {
+				t.Fatalf("got error %v, want a body read error", err)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("got error %v, want one matching context.DeadlineExceeded", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Fatalf("returned after %v, want about the 100ms timeout", elapsed)
+			}
+			if results != nil {
+				t.Fatalf("got results %#v alongside an error", results)
+			}
+		})
+	}
+}
+
+func TestMaxResponseBytesChunked(t *testing.T) {
+	// The trickle never ends, so only the limit can stop the read early.
+	srv := tricklingServer(t, `{"results": [`, time.Millisecond, 0)
+	c := NewClient(Config{BaseURL: srv.URL, MaxResponseBytes: 64, Timeout: 10 * time.Second})
+
+	start := time.Now()
+	_, err := c.Search(context.Background(), "docs", SearchRequest{Vector: []float32{1, 2}, TopK: 5})
+	if !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("got error %v, want ErrResponseTooLarge", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("returned after %v, want the limit to stop the read", elapsed)
+	}
+}
+
+func TestMaxResponseBytesContentLength(t *testing.T) {
+	// The body stalls after the headers, so a client that waited for it
+	// would hit the timeout instead.
+	srv := tricklingServer(t, "", 0, 1<<20)
+	c := NewClient(Config{BaseURL: srv.URL, MaxResponseBytes: 64, Timeout: 10 * time.Second})
+
+	start := time.Now()
+	_, err := c.Search(context.Background(), "docs", SearchRequest{Vector: []float32{1, 2}, TopK: 5})
+	if !errors.Is(err, ErrResponseTooLarge) || !strings.Contains(err.Error(), "Content-Length") {
+		t.Fatalf("got error %v, want ErrResponseTooLarge from the Content-Length", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("returned after %v, want the Content-Length to fail the call up front", elapsed)
+	}
+}
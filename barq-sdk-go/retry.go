@@ -0,0 +1,145 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Jitter selects how RetryConfig randomizes backoff delays, so that many
+// clients retrying against a recovering server spread out instead of
+// arriving in waves.
+type Jitter int
+
+const (
+	// JitterFull waits a uniformly random time in [0, d), where d is the
+	// exponential delay. It is the default.
+	JitterFull Jitter = iota
+	// JitterNone waits exactly d.
+	JitterNone
+	// JitterEqual waits d/2 plus a random time in [0, d/2), keeping a
+	// minimum backoff while still spreading retries.
+	JitterEqual
+)
+
+// RetryConfig enables automatic retries in Client. Retries share the single
+// deadline of the call (see Config.Timeout), so they never extend it.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled for each
+	// following one up to MaxDelay. Defaults are 100ms and 2s.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Jitter randomizes each delay; the zero value is JitterFull.
+	Jitter Jitter
+	// Rand is the source of jitter. Set a seeded one for deterministic
+	// delays in tests; nil uses the math/rand global source. Draws are
+	// serialized, so one Rand may be shared by concurrent calls.
+	Rand *rand.Rand
+	// MaxElapsed caps the wall-clock time from the first attempt until a
+	// retry may start; a retry that would begin later is not made and the
+	// last error is returned. It never interrupts an attempt in flight.
+	// Zero means no cap beyond the context deadline, which is always a hard
+	// upper bound.
+	MaxElapsed time.Duration
+	// Retryable overrides the default classification when set. It is
+	// called with the HTTP method, the request path and the error of the
+	// failed attempt.
+	Retryable func(method, path string, err error) bool
+}
+
+func (r *RetryConfig) delay(attempt int) time.Duration {
+	base, maxDelay := r.BaseDelay, r.MaxDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	if maxDelay <= 0 {
+		maxDelay = 2 * time.Second
+	}
+	d := base
+	for i := 1; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+	if d > maxDelay {
+		d = maxDelay
+	}
+
+	switch r.Jitter {
+	case JitterNone:
+		return d
+	case JitterEqual:
+		return d/2 + r.random(d/2)
+	default:
+		return r.random(d)
+	}
+}
+
+var jitterMu sync.Mutex
+
+// random returns a duration in [0, n).
+func (r *RetryConfig) random(n time.Duration) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	if r.Rand == nil {
+		return time.Duration(rand.Int63n(int64(n)))
+	}
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(r.Rand.Int63n(int64(n)))
+}
+
+// withinBudget reports whether a retry after wait, for a call that started
+// at start, would begin within MaxElapsed and before ctx's deadline.
+func (r *RetryConfig) withinBudget(ctx context.Context, start time.Time, wait time.Duration) bool {
+	next := time.Now().Add(wait)
+	if r.MaxElapsed > 0 && next.Sub(start) > r.MaxElapsed {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && !next.Before(deadline) {
+		return false
+	}
+	return true
+}
+
+// shouldRetry reports whether a failed attempt may be replayed. safe is set
+// for requests that are reads, such as searches sent as POST, or are
+// otherwise idempotent.
+func (r *RetryConfig) shouldRetry(method, path string, safe bool, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if r.Retryable != nil {
+		return r.Retryable(method, path, err)
+	}
+	return defaultRetryable(method, safe, err)
+}
+
+// defaultRetryable retries 429 and 503 for every method, since the server
+// rejected those requests without applying them. Network errors are
+// ambiguous—the request may have been applied before the connection
+// failed—so they are only retried for reads, including searches sent as
+// POST, and for requests carrying an idempotency key.
+func defaultRetryable(method string, safe bool, err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusServiceUnavailable
+	}
+	return method == http.MethodGet || method == http.MethodHead || safe
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,152 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stallingServer reads each request body and then, for the first failFor
+// requests, stalls before dropping the connection without answering, as a
+// proxy does when its upstream times out. The server has seen the whole
+// request by then, so a write may have been applied. Later requests get
+// an empty 200. It counts the requests whose body it read.
+func stallingServer(t *testing.T, failFor int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+		if atomic.AddInt32(&attempts, 1) > failFor {
+			w.Write([]byte(`{"results": []}`))
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &attempts
+}
+
+func retryClient(url string) *Client {
+	return NewClient(Config{
+		BaseURL: url,
+		Retry:   &RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	})
+}
+
+func TestInsertNotRetriedAfterMidFlightFailure(t *testing.T) {
+	srv, attempts := stallingServer(t, 1)
+	c := retryClient(srv.URL)
+
+	err := c.Insert(context.Background(), "docs", InsertRequest{ID: 1, Vector: []float32{1, 2}})
+	if err == nil {
+		t.Fatal("insert succeeded, want the dropped connection's error")
+	}
+	if n := atomic.LoadInt32(attempts); n != 1 {
+		t.Fatalf("server saw %d inserts, want 1: a plain insert must not be replayed", n)
+	}
+}
+
+func TestInsertNotRetriedAfterDeadline(t *testing.T) {
+	var attempts int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		atomic.AddInt32(&attempts, 1)
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	c := retryClient(srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.Insert(ctx, "docs", InsertRequest{ID: 1, Vector: []float32{1, 2}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Fatalf("server saw %d inserts, want 1", n)
+	}
+}
+
+func TestInsertWithIdempotencyKeyRetried(t *testing.T) {
+	srv, attempts := stallingServer(t, 1)
+	c := retryClient(srv.URL)
+
+	err := c.Insert(context.Background(), "docs", InsertRequest{ID: 1, Vector: []float32{1, 2}}, WithIdempotencyKey("doc-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(attempts); n != 2 {
+		t.Fatalf("server saw %d inserts, want 2", n)
+	}
+}
+
+func TestSearchRetriedAfterMidFlightFailure(t *testing.T) {
+	srv, attempts := stallingServer(t, 1)
+	c := retryClient(srv.URL)
+
+	results, err := c.Search(context.Background(), "docs", SearchRequest{Vector: []float32{1, 2}, TopK: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results == nil {
+		t.Fatal("got nil results, want an empty slice")
+	}
+	if n := atomic.LoadInt32(attempts); n != 2 {
+		t.Fatalf("server saw %d searches, want 2", n)
+	}
+}
+
+func TestInsertRetriedOnServiceUnavailable(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			http.Error(w, `{"error": "overloaded"}`, http.StatusServiceUnavailable)
+			return
+		}
+	}))
+	defer srv.Close()
+	c := retryClient(srv.URL)
+
+	if err := c.Insert(context.Background(), "docs", InsertRequest{ID: 1, Vector: []float32{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 2 {
+		t.Fatalf("server saw %d inserts, want 2", n)
+	}
+}
+
+func TestRetryableOverride(t *testing.T) {
+	srv, attempts := stallingServer(t, 1)
+	c := NewClient(Config{
+		BaseURL: srv.URL,
+		Retry: &RetryConfig{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			Retryable:   func(method, path string, err error) bool { return true },
+		},
+	})
+
+	if err := c.Insert(context.Background(), "docs", InsertRequest{ID: 1, Vector: []float32{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(attempts); n != 2 {
+		t.Fatalf("server saw %d inserts, want 2", n)
+	}
+}
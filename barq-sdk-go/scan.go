@@ -0,0 +1,124 @@
+package barq
+
+import (
+	"context"
+	"errors"
+)
+
+// VectorScanner walks every vector in a collection, batchSize documents per
+// request. It follows the listing cursor rather than an offset, so each page
+// costs the same however deep the scan goes, and it holds at most one page
+// in memory:
+//
+//	s := client.ScanVectors(ctx, "products", 1000)
+//	for s.Next() {
+//		id, v := s.ID(), s.Vector()
+//	}
+//	if err := s.Err(); err != nil { ... }
+//
+// Documents come in the server's storage order. That order is stable for a
+// collection that is not being written to, but it is not sorted by ID, and
+// documents inserted or deleted during a scan may or may not be seen.
+type VectorScanner struct {
+	ctx        context.Context
+	client     *Client
+	collection string
+	batchSize  int
+	opts       []CallOption
+
+	cursor  string // cursor of the page being read
+	next    string // cursor of the page after it
+	ids     []interface{}
+	vectors [][]float32
+	pos     int
+	started bool
+	done    bool
+	err     error
+}
+
+// ScanVectors returns a scanner over every (ID, vector) pair in collection.
+// A batchSize of zero uses the server's default page size.
+func (c *Client) ScanVectors(ctx context.Context, collection string, batchSize int, opts ...CallOption) *VectorScanner {
+	s := &VectorScanner{ctx: ctx, client: c, collection: collection, batchSize: batchSize, opts: opts}
+	if batchSize < 0 {
+		s.err = errors.New("barq: ScanVectors needs a non-negative batch size")
+	}
+	return s
+}
+
+// From makes the scan start at cursor, a value previously returned by
+// Cursor, instead of at the beginning. It must be called before Next.
+func (s *VectorScanner) From(cursor string) *VectorScanner {
+	if !s.started {
+		s.next = cursor
+	}
+	return s
+}
+
+// Next advances to the next vector, fetching a page when needed. It
+// returns false when the collection is exhausted or an error occurred.
+func (s *VectorScanner) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	if s.started {
+		s.pos++
+	}
+	for s.pos >= len(s.ids) {
+		if s.done {
+			return false
+		}
+		if err := s.fetch(); err != nil {
+			s.err = err
+			return false
+		}
+	}
+	return true
+}
+
+func (s *VectorScanner) fetch() error {
+	page, err := s.client.ListDocuments(s.ctx, s.collection, s.next, s.batchSize, s.opts...)
+	if err != nil {
+		return err
+	}
+	s.started = true
+	s.cursor = s.next
+	s.next = page.NextCursor
+	s.done = page.NextCursor == ""
+
+	// Keep only IDs and vectors so payloads are not held for the page.
+	s.ids = s.ids[:0]
+	s.vectors = s.vectors[:0]
+	for _, doc := range page.Documents {
+		s.ids = append(s.ids, plainID(doc.ID))
+		s.vectors = append(s.vectors, doc.Vector)
+	}
+	s.pos = 0
+	return nil
+}
+
+// ID returns the ID of the document Next advanced to.
+func (s *VectorScanner) ID() interface{} {
+	return s.ids[s.pos]
+}
+
+// Vector returns the vector of the document Next advanced to.
+func (s *VectorScanner) Vector() []float32 {
+	return s.vectors[s.pos]
+}
+
+// Cursor returns a value that From accepts to resume the scan. It points at
+// the start of the current page, so a resumed scan repeats up to
+// batchSize-1 documents already seen; deduplicate by ID if that matters.
+// After a failed fetch it points at the page that failed.
+func (s *VectorScanner) Cursor() string {
+	if !s.started || (!s.done && s.pos >= len(s.ids)) {
+		return s.next
+	}
+	return s.cursor
+}
+
+// Err returns the error that stopped the scan, if any.
+func (s *VectorScanner) Err() error {
+	return s.err
+}
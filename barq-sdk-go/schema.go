@@ -0,0 +1,234 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultMetadataTTL is how long collection metadata stays cached when
+// Config.MetadataTTL is zero.
+const DefaultMetadataTTL = 5 * time.Minute
+
+// collectionCache holds collection metadata learned from CreateCollection
+// and DescribeCollection. Entries older than ttl are treated as missing;
+// a ttl <= 0 keeps them until invalidated.
+type collectionCache struct {
+	ttl time.Duration
+	m   sync.Map
+}
+
+type cacheEntry struct {
+	info    *CollectionInfo
+	fetched time.Time
+}
+
+func (c *collectionCache) get(name string) (*CollectionInfo, bool) {
+	v, ok := c.m.Load(name)
+	if !ok {
+		return nil, false
+	}
+	e := v.(cacheEntry)
+	if c.ttl > 0 && time.Since(e.fetched) > c.ttl {
+		c.m.Delete(name)
+		return nil, false
+	}
+	return e.info, true
+}
+
+func (c *collectionCache) set(info *CollectionInfo) {
+	c.m.Store(info.Name, cacheEntry{info: info, fetched: time.Now()})
+}
+
+func (c *collectionCache) invalidate(name string) {
+	c.m.Delete(name)
+}
+
+// RefreshCollectionMeta drops any cached metadata for name and describes the
+// collection again. Call it after changing a collection's schema out of band.
+func (c *Client) RefreshCollectionMeta(ctx context.Context, name string) (*CollectionInfo, error) {
+	c.meta.invalidate(name)
+	return c.DescribeCollection(ctx, name)
+}
+
+// collectionInfo returns cached metadata for name, describing the collection
+// on first use.
+func (c *Client) collectionInfo(ctx context.Context, name string) (*CollectionInfo, error) {
+	if info, ok := c.meta.get(name); ok {
+		return info, nil
+	}
+	return c.DescribeCollection(ctx, name)
+}
+
+func (info *CollectionInfo) indexedFields() map[string]bool {
+	fields := make(map[string]bool)
+	for _, f := range info.TextFields {
+		if f.Indexed {
+			fields[f.Name] = true
+		}
+	}
+	for _, f := range info.PayloadFields {
+		if f.Indexed {
+			fields[f.Name] = true
+		}
+	}
+	return fields
+}
+
+// validateVectors checks named vectors against the dimensions declared in
+// the collection schema.
+func (c *Client) validateVectors(ctx context.Context, collection string, vectors map[string][]float32) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	info, err := c.collectionInfo(ctx, collection)
+	if err != nil {
+		return fmt.Errorf("validate vectors: %w", err)
+	}
+	dims := make(map[string]int, len(info.Vectors))
+	for _, v := range info.Vectors {
+		dims[v.Name] = v.Dimension
+	}
+
+	for name, v := range vectors {
+		dim, ok := dims[name]
+		if !ok {
+			return fmt.Errorf("%w: %q in collection %q", ErrUnknownVector, name, collection)
+		}
+		if len(v) != dim {
+			return fmt.Errorf("%w: vector %q has %d dimensions, collection %q expects %d",
+				ErrDimensionMismatch, name, len(v), collection, dim)
+		}
+	}
+	return nil
+}
+
+// validateTextField rejects a TextField the collection does not declare. It
+// only consults metadata already cached and never describes the collection.
+func (c *Client) validateTextField(collection, field string) error {
+	if field == "" {
+		return nil
+	}
+	info, ok := c.meta.get(collection)
+	if !ok {
+		return nil
+	}
+	for _, f := range info.TextFields {
+		if f.Name == field {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q in collection %q", ErrUnknownTextField, field, collection)
+}
+
+// DimensionPolicy is what a search does with a query vector whose length
+// differs from the collection's cached dimension. See Config.DimensionPolicy.
+type DimensionPolicy int
+
+const (
+	// DimensionStrict fails with ErrDimensionMismatch before sending.
+	DimensionStrict DimensionPolicy = iota
+	// DimensionPadTruncate zero-pads short vectors and truncates long ones,
+	// reporting each adjustment through Config.Warn. Meant for
+	// experimenting with embedding models, not production.
+	DimensionPadTruncate
+	// DimensionUnchecked sends vectors as they are.
+	DimensionUnchecked
+)
+
+// searchVector applies Config.DimensionPolicy to a query vector matched
+// against field (empty for the default vector). Like validateTextField it
+// only consults cached metadata.
+func (c *Client) searchVector(collection, field string, v []float32) ([]float32, error) {
+	if len(v) == 0 || c.config.DimensionPolicy == DimensionUnchecked {
+		return v, nil
+	}
+	info, ok := c.meta.get(collection)
+	if !ok {
+		return v, nil
+	}
+	want := info.Dimension
+	if field != "" {
+		want = 0
+		for _, nv := range info.Vectors {
+			if nv.Name == field {
+				want = nv.Dimension
+			}
+		}
+	}
+	if want <= 0 || len(v) == want {
+		return v, nil
+	}
+
+	if c.config.DimensionPolicy != DimensionPadTruncate {
+		return nil, fmt.Errorf("%w: query vector has %d dimensions, collection %q expects %d",
+			ErrDimensionMismatch, len(v), collection, want)
+	}
+	adjusted := make([]float32, want)
+	copy(adjusted, v)
+	c.warn(fmt.Sprintf("barq: query vector for collection %q resized from %d to %d dimensions", collection, len(v), want))
+	return adjusted, nil
+}
+
+// validateFilter enforces Config.ValidateFilters for a search filter.
+func (c *Client) validateFilter(ctx context.Context, collection string, filter interface{}) error {
+	if !c.config.ValidateFilters || filter == nil {
+		return nil
+	}
+
+	info, err := c.collectionInfo(ctx, collection)
+	if err != nil {
+		return fmt.Errorf("validate filter: %w", err)
+	}
+	fields, err := filterFields(filter)
+	if err != nil {
+		return fmt.Errorf("validate filter: %w", err)
+	}
+
+	indexed := info.indexedFields()
+	for _, f := range fields {
+		if !indexed[f] {
+			return fmt.Errorf("%w: %q in collection %q", ErrUnindexedFilterField, f, collection)
+		}
+	}
+	return nil
+}
+
+// filterFields lists the field names referenced anywhere in a filter by
+// walking its JSON form for "field" keys, so it works for maps, structs and
+// builder values alike.
+func filterFields(filter interface{}) ([]string, error) {
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	seen := make(map[string]bool)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if name, ok := v["field"].(string); ok && !seen[name] {
+				seen[name] = true
+				fields = append(fields, name)
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(tree)
+	return fields, nil
+}
@@ -0,0 +1,116 @@
+package barq
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SearchBuilder builds a SearchRequest fluently and checks it in Build:
+//
+//	req, err := barq.NewSearch().Vector(v).Query(q).TopK(10).Filter(f).Build()
+//
+// SearchRequest stays usable directly; the builder only adds the checks.
+type SearchBuilder struct {
+	req SearchRequest
+}
+
+func NewSearch() *SearchBuilder {
+	return &SearchBuilder{}
+}
+
+func (b *SearchBuilder) Vector(v []float32) *SearchBuilder {
+	b.req.Vector = v
+	return b
+}
+
+func (b *SearchBuilder) VectorField(name string) *SearchBuilder {
+	b.req.VectorField = name
+	return b
+}
+
+func (b *SearchBuilder) Query(q string) *SearchBuilder {
+	b.req.Query = q
+	return b
+}
+
+func (b *SearchBuilder) TextField(name string) *SearchBuilder {
+	b.req.TextField = name
+	return b
+}
+
+func (b *SearchBuilder) MinShouldMatch(n int) *SearchBuilder {
+	b.req.MinShouldMatch = n
+	return b
+}
+
+func (b *SearchBuilder) Fuzziness(edits int) *SearchBuilder {
+	b.req.Fuzziness = edits
+	return b
+}
+
+func (b *SearchBuilder) Highlight() *SearchBuilder {
+	b.req.Highlight = true
+	return b
+}
+
+func (b *SearchBuilder) IncludeVector() *SearchBuilder {
+	b.req.IncludeVector = true
+	return b
+}
+
+func (b *SearchBuilder) ScoreOnly() *SearchBuilder {
+	b.req.ScoreOnly = true
+	return b
+}
+
+func (b *SearchBuilder) NormalizeScores() *SearchBuilder {
+	b.req.NormalizeScores = true
+	return b
+}
+
+func (b *SearchBuilder) Facets(fields ...string) *SearchBuilder {
+	b.req.Facets = append(b.req.Facets, fields...)
+	return b
+}
+
+func (b *SearchBuilder) TopK(k int) *SearchBuilder {
+	b.req.TopK = k
+	return b
+}
+
+func (b *SearchBuilder) Filter(f interface{}) *SearchBuilder {
+	b.req.Filter = f
+	return b
+}
+
+func (b *SearchBuilder) FilterMode(mode FilterMode) *SearchBuilder {
+	b.req.FilterMode = mode
+	return b
+}
+
+// Build returns the request, or an error if it has no vector and no query,
+// a TopK that is not positive, a Fuzziness out of range, options that need
+// a query or a filter without one, or ScoreOnly together with IncludeVector
+// or Highlight.
+func (b *SearchBuilder) Build() (SearchRequest, error) {
+	req := b.req
+	switch {
+	case len(req.Vector) == 0 && req.Query == "":
+		return req, errors.New("barq: search needs a vector, a query or both")
+	case req.TopK <= 0:
+		return req, errors.New("barq: search TopK must be positive")
+	case req.MinShouldMatch < 0:
+		return req, errors.New("barq: MinShouldMatch must not be negative")
+	case req.Fuzziness < 0 || req.Fuzziness > MaxFuzziness:
+		return req, fmt.Errorf("barq: Fuzziness must be 0 to %d", MaxFuzziness)
+	case req.Query == "" && (req.TextField != "" || req.MinShouldMatch > 0 || req.Fuzziness > 0 || req.Highlight):
+		return req, errors.New("barq: TextField, MinShouldMatch, Fuzziness and Highlight need a query")
+	case len(req.Vector) == 0 && req.VectorField != "":
+		return req, errors.New("barq: VectorField needs a vector")
+	case req.Filter == nil && req.FilterMode != "":
+		return req, errors.New("barq: FilterMode needs a filter")
+	case req.ScoreOnly && (req.IncludeVector || req.Highlight):
+		return req, errors.New("barq: ScoreOnly cannot be combined with IncludeVector or Highlight")
+	}
+	return req, nil
+}
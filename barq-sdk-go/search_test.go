@@ -0,0 +1,262 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// jsonServer answers search requests with body and status, and describe
+// requests with 404, so no collection metadata is cached.
+func jsonServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSearchNoHitsIsEmptySlice(t *testing.T) {
+	for name, body := range map[string]string{
+		"empty list": `{"results": []}`,
+		"null list":  `{"results": null}`,
+		"no list":    `{}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient(Config{BaseURL: jsonServer(t, http.StatusOK, body).URL})
+			req := SearchRequest{Vector: []float32{1, 2}, TopK: 5}
+
+			results, err := c.Search(context.Background(), "docs", req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results == nil || len(results) != 0 {
+				t.Fatalf("Search: got %#v, want a non-nil empty slice", results)
+			}
+
+			resp, err := c.SearchWithMeta(context.Background(), "docs", req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Results == nil || len(resp.Results) != 0 {
+				t.Fatalf("SearchWithMeta: got %#v, want a non-nil empty slice", resp.Results)
+			}
+		})
+	}
+}
+
+func TestBatchSearchNoHitsIsEmptySlice(t *testing.T) {
+	c := NewClient(Config{BaseURL: jsonServer(t, http.StatusOK, `{"results": [{"hits": []}, {}]}`).URL})
+	sets, err := c.BatchSearch(context.Background(), "docs", []SearchRequest{
+		{Vector: []float32{1, 2}, TopK: 5},
+		{Vector: []float32{3, 4}, TopK: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sets) != 2 {
+		t.Fatalf("got %d result sets, want 2", len(sets))
+	}
+	for i, set := range sets {
+		if set == nil || len(set) != 0 {
+			t.Errorf("set %d: got %#v, want a non-nil empty slice", i, set)
+		}
+	}
+}
+
+func TestSearchErrorHasNoResults(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"server error", http.StatusInternalServerError, `{"error": "index unavailable"}`},
+		{"missing collection", http.StatusNotFound, `{"error": "collection not found"}`},
+		{"malformed body", http.StatusOK, `{"results": [`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(Config{BaseURL: jsonServer(t, tt.status, tt.body).URL})
+			results, err := c.Search(context.Background(), "docs", SearchRequest{Vector: []float32{1, 2}, TopK: 5})
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			if results != nil {
+				t.Fatalf("got results %#v alongside error %v", results, err)
+			}
+		})
+	}
+}
+
+func TestSearchUnreachableServer(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	c := NewClient(Config{BaseURL: url})
+	results, err := c.Search(context.Background(), "docs", SearchRequest{Vector: []float32{1, 2}, TopK: 5})
+	if err == nil || !strings.Contains(err.Error(), "connect") {
+		t.Fatalf("got error %v, want a connection error", err)
+	}
+	if results != nil {
+		t.Fatalf("got results %#v alongside an error", results)
+	}
+}
+
+func TestBatchSearchKeepsQueriesSeparate(t *testing.T) {
+	var got []map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Queries []map[string]json.RawMessage `json:"queries"`
+			TopK    int                          `json:"top_k"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		got = body.Queries
+		// Answer every query with the batch-wide top_k, as a server that
+		// ignores the per-query one would, tagging each hit with its query.
+		var results []map[string][]SearchResult
+		for i := range body.Queries {
+			var h []SearchResult
+			for j := 0; j < body.TopK; j++ {
+				h = append(h, SearchResult{ID: fmt.Sprintf("q%d-%d", i, j), Score: float32(body.TopK - j)})
+			}
+			results = append(results, map[string][]SearchResult{"hits": h})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	defer srv.Close()
+	c := NewClient(Config{BaseURL: srv.URL})
+
+	reqs := []SearchRequest{
+		{Vector: []float32{1, 0}, TopK: 5, Filter: Eq("tenant", "a")},
+		{Vector: []float32{0, 1}, TopK: 1},
+		{Vector: []float32{1, 1}, TopK: 3, Filter: Gt("year", 2020), VectorField: "title"},
+	}
+	sets, err := c.BatchSearch(context.Background(), "docs", reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantQueries := []map[string]string{
+		{"top_k": `5`, "filter": `{"field":"tenant","op":"eq","value":"a"}`},
+		{"top_k": `1`},
+		{"top_k": `3`, "filter": `{"field":"year","op":"gt","value":2020}`, "vector_field": `"title"`},
+	}
+	if len(got) != len(wantQueries) {
+		t.Fatalf("server got %d queries, want %d", len(got), len(wantQueries))
+	}
+	for i, want := range wantQueries {
+		for _, key := range []string{"top_k", "filter", "vector_field"} {
+			if g, w := string(got[i][key]), want[key]; g != w {
+				t.Errorf("query %d %s: sent %s, want %s", i, key, g, w)
+			}
+		}
+	}
+
+	if len(sets) != len(reqs) {
+		t.Fatalf("got %d result sets, want %d", len(sets), len(reqs))
+	}
+	for i, set := range sets {
+		if len(set) != reqs[i].TopK {
+			t.Errorf("set %d: got %d hits, want its own TopK of %d", i, len(set), reqs[i].TopK)
+		}
+		for j, r := range set {
+			if want := fmt.Sprintf("q%d-%d", i, j); r.ID != want {
+				t.Errorf("set %d hit %d: got %v, want %v", i, j, r.ID, want)
+			}
+		}
+	}
+}
+
+func TestSearchSendsNoHiddenDescribe(t *testing.T) {
+	var gets int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+			w.Write([]byte(`{"name": "docs", "dimension": 2, "metric": "L2"}`))
+			return
+		}
+		w.Write([]byte(`{"results": [{"id": 1, "score": -1}]}`))
+	}))
+	defer srv.Close()
+	c := NewClient(Config{BaseURL: srv.URL})
+	req := SearchRequest{Vector: []float32{1, 2}, TopK: 5}
+
+	for i := 0; i < 2; i++ {
+		results, err := c.Search(context.Background(), "docs", req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results[0].Similarity != results[0].Score {
+			t.Fatalf("got Similarity %v with no cached metric, want the raw score", results[0].Similarity)
+		}
+	}
+	if n := atomic.LoadInt32(&gets); n != 0 {
+		t.Fatalf("searches sent %d describe requests, want none", n)
+	}
+	if s := c.Stats(); s.Requests != 2 || s.Errors != 0 {
+		t.Fatalf("got %d requests and %d errors, want only the 2 searches", s.Requests, s.Errors)
+	}
+
+	if _, err := c.DescribeCollection(context.Background(), "docs"); err != nil {
+		t.Fatal(err)
+	}
+	results, err := c.Search(context.Background(), "docs", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Similarity != 0.5 {
+		t.Fatalf("got Similarity %v after describing the L2 collection, want 0.5", results[0].Similarity)
+	}
+}
+
+func TestSearchPayloadTooLarge(t *testing.T) {
+	body := `{"results": [{"id": 1, "score": 1, "payload": {"k": "small"}},` +
+		`{"id": 2, "score": 0.5, "payload": {"k": "` + strings.Repeat("x", 100) + `"}}]}`
+	c := NewClient(Config{BaseURL: jsonServer(t, http.StatusOK, body).URL, MaxPayloadBytes: 64})
+	req := SearchRequest{Vector: []float32{1, 2}, TopK: 5}
+
+	results, err := c.Search(context.Background(), "docs", req)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("Search: got error %v, want ErrPayloadTooLarge", err)
+	}
+	if results != nil {
+		t.Fatalf("Search: got results %#v alongside an error", results)
+	}
+
+	var seen []interface{}
+	err = c.SearchEach(context.Background(), "docs", req, func(r SearchResult) error {
+		seen = append(seen, r.ID)
+		return nil
+	})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("SearchEach: got error %v, want ErrPayloadTooLarge", err)
+	}
+	if len(seen) != 1 {
+		t.Fatalf("SearchEach: got results %v, want only the hit before the oversized one", seen)
+	}
+
+	batch := `{"results": [{"hits": [{"id": 2, "score": 0.5, "payload": {"k": "` + strings.Repeat("x", 100) + `"}}]}]}`
+	c = NewClient(Config{BaseURL: jsonServer(t, http.StatusOK, batch).URL, MaxPayloadBytes: 64})
+	if _, err := c.BatchSearch(context.Background(), "docs", []SearchRequest{req}); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("BatchSearch: got error %v, want ErrPayloadTooLarge", err)
+	}
+}
@@ -0,0 +1,81 @@
+package barq
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SoftDelete makes DeleteDocument mark the document deleted instead of
+// removing it. A soft-deleted document is left out of searches but can
+// still be fetched with GetDocument (with Document.DeletedAt set) and
+// brought back with RestoreDocument until PurgeDeleted removes it.
+//
+// Soft deletes need a server that lists the "soft_delete" feature in
+// ServerInfo. Against any other server DeleteDocument fails with
+// ErrSoftDeleteUnsupported without sending the delete, since a server that
+// ignored the request's soft flag would delete the document for good.
+// Other methods ignore this option.
+func SoftDelete() CallOption {
+	return func(o *callOptions) { o.softDelete = true }
+}
+
+func (c *Client) checkSoftDelete(ctx context.Context) error {
+	supported, err := c.softDeleteSupport.check(func() (bool, error) {
+		info, err := c.ServerInfo(ctx)
+		if err != nil {
+			return false, err
+		}
+		return info.HasFeature("soft_delete"), nil
+	})
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return ErrSoftDeleteUnsupported
+	}
+	return nil
+}
+
+// RestoreDocument undoes a soft delete. Restoring a document that was not
+// soft-deleted is a no-op on the server; one that was purged or never
+// existed yields an error matching ErrNotFound.
+func (c *Client) RestoreDocument(ctx context.Context, collection string, id interface{}, opts ...CallOption) error {
+	if err := c.checkCollectionName(collection); err != nil {
+		return err
+	}
+	if err := c.checkWritable(collection); err != nil {
+		return err
+	}
+	path := collectionPath(collection, "documents", url.PathEscape(idKey(id)), "restore")
+	_, err := c.request(ctx, "POST", path, nil, opts...)
+	return err
+}
+
+// PurgeDeleted permanently removes documents soft-deleted more than
+// olderThan ago, zero meaning all of them, and returns how many went.
+func (c *Client) PurgeDeleted(ctx context.Context, collection string, olderThan time.Duration, opts ...CallOption) (int, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return 0, err
+	}
+	if err := c.checkWritable(collection); err != nil {
+		return 0, err
+	}
+	path := collectionPath(collection, "purge_deleted")
+	if olderThan > 0 {
+		path += "?older_than_seconds=" + strconv.FormatInt(int64(olderThan/time.Second), 10)
+	}
+	respBytes, err := c.request(ctx, "POST", path, nil, opts...)
+	if err != nil {
+		return 0, err
+	}
+	var resp struct {
+		Purged int `json:"purged"`
+	}
+	if err := c.decode(respBytes, &resp); err != nil {
+		return 0, fmt.Errorf("purge deleted: %w", err)
+	}
+	return resp.Purged, nil
+}
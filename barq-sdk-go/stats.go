@@ -0,0 +1,94 @@
+package barq
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// ClientStats is a snapshot of a Client's traffic counters.
+type ClientStats struct {
+	// Requests counts HTTP requests sent, each retry included, and Errors
+	// those of them that failed, by transport error or error status.
+	Requests int64
+	Errors   int64
+	// BytesSent and BytesReceived count request and response body bytes
+	// as they went over the wire, so after gzip compression and excluding
+	// headers. BytesReceived only counts what has been read.
+	BytesSent     int64
+	BytesReceived int64
+	// DocumentsInserted counts successful inserts, and SearchResults the
+	// hits returned by searches.
+	DocumentsInserted int64
+	SearchResults     int64
+	// Failovers counts switches of the active endpoint, and
+	// ActiveEndpoint is the base URL requests currently go to first;
+	// see Config.FallbackURLs.
+	Failovers      int64
+	ActiveEndpoint string
+	// Hedges counts the extra copies sent by WithHedging; they are also
+	// in Requests. Copies cancelled because another one won are not
+	// counted in Errors.
+	Hedges int64
+}
+
+// clientStats holds the live counters, updated atomically so a Client can
+// be polled while in use.
+type clientStats struct {
+	requests          int64
+	errors            int64
+	bytesSent         int64
+	bytesReceived     int64
+	documentsInserted int64
+	searchResults     int64
+	failovers         int64
+	hedges            int64
+}
+
+// Stats returns the counters accumulated since the client was created or
+// last reset. It is cheap and safe to call concurrently with requests.
+func (c *Client) Stats() ClientStats {
+	s := &c.stats
+	return ClientStats{
+		Requests:          atomic.LoadInt64(&s.requests),
+		Errors:            atomic.LoadInt64(&s.errors),
+		BytesSent:         atomic.LoadInt64(&s.bytesSent),
+		BytesReceived:     atomic.LoadInt64(&s.bytesReceived),
+		DocumentsInserted: atomic.LoadInt64(&s.documentsInserted),
+		SearchResults:     atomic.LoadInt64(&s.searchResults),
+		Failovers:         atomic.LoadInt64(&s.failovers),
+		Hedges:            atomic.LoadInt64(&s.hedges),
+		ActiveEndpoint:    c.endpoint(),
+	}
+}
+
+// ResetStats zeroes the counters and returns their values just before, so
+// polling with ResetStats yields per-interval figures without losing
+// updates in between. ActiveEndpoint is not a counter and is kept. Each
+// counter is swapped on its own; the snapshot is not a single atomic cut
+// across all of them.
+func (c *Client) ResetStats() ClientStats {
+	s := &c.stats
+	return ClientStats{
+		Requests:          atomic.SwapInt64(&s.requests, 0),
+		Errors:            atomic.SwapInt64(&s.errors, 0),
+		BytesSent:         atomic.SwapInt64(&s.bytesSent, 0),
+		BytesReceived:     atomic.SwapInt64(&s.bytesReceived, 0),
+		DocumentsInserted: atomic.SwapInt64(&s.documentsInserted, 0),
+		SearchResults:     atomic.SwapInt64(&s.searchResults, 0),
+		Failovers:         atomic.SwapInt64(&s.failovers, 0),
+		Hedges:            atomic.SwapInt64(&s.hedges, 0),
+		ActiveEndpoint:    c.endpoint(),
+	}
+}
+
+// countingReader adds the bytes read through it to *n.
+type countingReader struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddInt64(r.n, int64(n))
+	return n, err
+}
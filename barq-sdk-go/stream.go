@@ -0,0 +1,143 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// SearchEach runs a search like Search but decodes the response
+// incrementally, calling fn for each result as it is read. Use it for large
+// result sets with payloads that should not be buffered in memory at once.
+// Returning an error from fn stops decoding and is returned as is.
+func (c *Client) SearchEach(ctx context.Context, collection string, req SearchRequest, fn func(SearchResult) error, opts ...CallOption) error {
+	req, err := c.prepareSearch(ctx, collection, req)
+	if err != nil {
+		return err
+	}
+	metric := c.collectionMetric(collection)
+	opts = append(opts[:len(opts):len(opts)], readCall())
+	resp, err := c.do(ctx, "POST", searchPath(collection, req), searchBody(req), opts...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return streamArray(resp.Body, "results", nil, func(dec *json.Decoder) error {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		var r SearchResult
+		if err := c.decode(raw, &r); err != nil {
+			return err
+		}
+		if err := c.checkResultPayloads([]SearchResult{r}); err != nil {
+			return err
+		}
+		if req.ScoreOnly {
+			r.scoreOnly()
+		}
+		r.Similarity = metric.Similarity(r.Score)
+		atomic.AddInt64(&c.stats.searchResults, 1)
+		return fn(r)
+	})
+}
+
+// ListDocumentsEach lists one page of documents like ListDocuments but
+// decodes the response incrementally, calling fn for each document as it is
+// read, and returns the cursor of the next page. Use it with large limits,
+// whose pages should not be buffered in memory at once. Returning an error
+// from fn stops decoding and is returned as is.
+func (c *Client) ListDocumentsEach(ctx context.Context, collection, cursor string, limit int, fn func(Document) error, opts ...CallOption) (string, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return "", err
+	}
+	resp, err := c.do(ctx, "GET", listDocumentsPath(collection, cursor, limit), nil, opts...)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var next string
+	err = streamArray(resp.Body, "documents", map[string]interface{}{"next_cursor": &next}, func(dec *json.Decoder) error {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		var doc Document
+		if err := c.decode(raw, &doc); err != nil {
+			return err
+		}
+		if err := c.checkPayloads(&doc); err != nil {
+			return err
+		}
+		return fn(doc)
+	})
+	if err != nil {
+		return "", err
+	}
+	return next, nil
+}
+
+// streamArray walks the top-level JSON object read from r and calls fn once
+// per element of the array stored under key, leaving the decoder positioned
+// at that element. Other keys are decoded into the matching entry of fields,
+// or skipped.
+func streamArray(r io.Reader, key string, fields map[string]interface{}, fn func(*json.Decoder) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if name, _ := tok.(string); name != key {
+			var dst interface{} = new(json.RawMessage)
+			if v, ok := fields[name]; ok {
+				dst = v
+			}
+			if err := dec.Decode(dst); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok == nil {
+			continue
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return fmt.Errorf("decode %q: expected array, got %v", key, tok)
+		}
+		for dec.More() {
+			if err := fn(dec); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("decode response: expected %q, got %v", want, tok)
+	}
+	return nil
+}
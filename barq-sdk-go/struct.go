@@ -0,0 +1,110 @@
+package barq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// InsertStruct inserts obj, a struct or pointer to one, whose document ID
+// and vector are the exported fields tagged `barq:"id"` and `barq:"vector"`:
+//
+//	type Article struct {
+//		ID        string    `json:"id" barq:"id"`
+//		Embedding []float32 `json:"embedding" barq:"vector"`
+//		Title     string    `json:"title"`
+//	}
+//
+// The payload is obj encoded with encoding/json, minus those two fields.
+// Exactly one field must carry each tag, and the vector must be a
+// []float32. Only obj's own fields are considered, not those of embedded
+// structs.
+func (c *Client) InsertStruct(ctx context.Context, collection string, obj interface{}, opts ...CallOption) error {
+	req, err := structInsertRequest(obj)
+	if err != nil {
+		return err
+	}
+	return c.Insert(ctx, collection, req, opts...)
+}
+
+func structInsertRequest(obj interface{}) (InsertRequest, error) {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return InsertRequest{}, errors.New("barq: InsertStruct needs a non-nil struct")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return InsertRequest{}, fmt.Errorf("barq: InsertStruct needs a struct, got %T", obj)
+	}
+
+	t := v.Type()
+	idField, vectorField := -1, -1
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("barq")
+		if tag == "" {
+			continue
+		}
+		if f.PkgPath != "" {
+			return InsertRequest{}, fmt.Errorf("barq: %s.%s is tagged %q but unexported", t, f.Name, tag)
+		}
+		switch tag {
+		case "id":
+			if idField >= 0 {
+				return InsertRequest{}, fmt.Errorf("barq: %s has more than one field tagged barq:\"id\"", t)
+			}
+			idField = i
+		case "vector":
+			if vectorField >= 0 {
+				return InsertRequest{}, fmt.Errorf("barq: %s has more than one field tagged barq:\"vector\"", t)
+			}
+			if f.Type != reflect.TypeOf([]float32(nil)) {
+				return InsertRequest{}, fmt.Errorf("barq: %s.%s is tagged barq:\"vector\" but is %s, not []float32", t, f.Name, f.Type)
+			}
+			vectorField = i
+		default:
+			return InsertRequest{}, fmt.Errorf("barq: %s.%s has unknown tag barq:%q", t, f.Name, tag)
+		}
+	}
+	if idField < 0 {
+		return InsertRequest{}, fmt.Errorf("barq: %s has no field tagged barq:\"id\"", t)
+	}
+	if vectorField < 0 {
+		return InsertRequest{}, fmt.Errorf("barq: %s has no field tagged barq:\"vector\"", t)
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return InsertRequest{}, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return InsertRequest{}, fmt.Errorf("barq: %s does not encode as a JSON object: %w", t, err)
+	}
+	delete(fields, jsonFieldName(t.Field(idField)))
+	delete(fields, jsonFieldName(t.Field(vectorField)))
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return InsertRequest{}, err
+	}
+
+	return InsertRequest{
+		ID:      v.Field(idField).Interface(),
+		Vector:  v.Field(vectorField).Interface().([]float32),
+		Payload: payload,
+	}, nil
+}
+
+// jsonFieldName is the object key encoding/json uses for f.
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+package barq
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Txn buffers inserts and deletes against one collection and sends them
+// together on Commit, for the server to apply all or none of them. Nothing
+// reaches the server before Commit, so reads in the meantime, including
+// this client's, do not see the buffered writes. A Txn is safe for
+// concurrent use; operations are applied in the order they were added.
+type Txn struct {
+	client     *Client
+	collection string
+
+	mu   sync.Mutex
+	ops  []txnOp
+	done bool
+}
+
+// txnOp is one buffered operation in the wire shape of the transaction
+// endpoint.
+type txnOp struct {
+	Op       string      `json:"op"`
+	Document interface{} `json:"document,omitempty"`
+	ID       interface{} `json:"id,omitempty"`
+}
+
+// Transaction starts a transaction on collection. It needs a server that
+// lists the "transactions" feature in ServerInfo and exposes POST
+// /collections/{name}/transaction; against any other server it fails with
+// ErrTransactionsUnsupported, so writes are never applied piecemeal by
+// mistake.
+func (c *Client) Transaction(ctx context.Context, collection string) (*Txn, error) {
+	if err := c.checkCollectionName(collection); err != nil {
+		return nil, err
+	}
+	if err := c.checkWritable(collection); err != nil {
+		return nil, err
+	}
+	supported, err := c.txnSupport.check(func() (bool, error) {
+		info, err := c.ServerInfo(ctx)
+		if err != nil {
+			return false, err
+		}
+		return info.HasFeature("transactions"), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		return nil, ErrTransactionsUnsupported
+	}
+	return &Txn{client: c, collection: collection}, nil
+}
+
+// Insert adds an insert to the transaction. The request is checked as
+// Client.Insert checks it, so an invalid vector fails here rather than
+// failing the whole Commit.
+func (t *Txn) Insert(req InsertRequest) error {
+	if err := checkVectors(req.Vector, req.Vectors); err != nil {
+		return err
+	}
+	return t.add(txnOp{Op: "insert", Document: t.client.insertBody(req)})
+}
+
+// Delete adds a delete of the document with id to the transaction.
+func (t *Txn) Delete(id interface{}) error {
+	return t.add(txnOp{Op: "delete", ID: id})
+}
+
+func (t *Txn) add(op txnOp) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return ErrTxnDone
+	}
+	t.ops = append(t.ops, op)
+	return nil
+}
+
+// Commit sends the buffered operations for the server to apply atomically
+// and ends the transaction. On an error other than one from the server,
+// such as a timeout, the outcome is unknown; pass WithIdempotencyKey to make
+// the commit safe to retry. Committing an empty transaction sends nothing.
+func (t *Txn) Commit(ctx context.Context, opts ...CallOption) error {
+	t.mu.Lock()
+	if t.done {
+		t.mu.Unlock()
+		return ErrTxnDone
+	}
+	t.done = true
+	ops := t.ops
+	t.ops = nil
+	t.mu.Unlock()
+
+	if len(ops) == 0 {
+		return nil
+	}
+	body := map[string]interface{}{"operations": ops}
+	_, err := t.client.request(ctx, "POST", collectionPath(t.collection, "transaction"), body, opts...)
+	if err != nil {
+		return err
+	}
+	var inserted int64
+	for _, op := range ops {
+		if op.Op == "insert" {
+			inserted++
+		}
+	}
+	atomic.AddInt64(&t.client.stats.documentsInserted, inserted)
+	return nil
+}
+
+// Rollback discards the buffered operations and ends the transaction. The
+// server never saw them, so there is nothing to undo there. After Commit or
+// an earlier Rollback it returns ErrTxnDone and changes nothing.
+func (t *Txn) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return ErrTxnDone
+	}
+	t.done = true
+	t.ops = nil
+	return nil
+}
+
+// Len returns how many operations are buffered.
+func (t *Txn) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.ops)
+}
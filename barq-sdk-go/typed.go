@@ -0,0 +1,87 @@
+package barq
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TypedDocument is a Document whose payload has been decoded into T.
+type TypedDocument[T any] struct {
+	ID        interface{}
+	Vector    []float32
+	Vectors   map[string][]float32
+	Payload   T
+	Version   int64
+	DeletedAt *time.Time
+}
+
+// TypedDocumentPage is one page of ListDocumentsTyped.
+type TypedDocumentPage[T any] struct {
+	Documents []TypedDocument[T]
+	// NextCursor continues the listing; it is empty on the last page.
+	NextCursor string
+	// Errors holds the documents skipped under DecodeContinue.
+	Errors []DecodeError
+}
+
+// DecodePolicy says what ListDocumentsTyped does with a payload that does
+// not decode into the requested type.
+type DecodePolicy int
+
+const (
+	// DecodeFailFast returns the first decode error. It is the default.
+	DecodeFailFast DecodePolicy = iota
+	// DecodeContinue skips the document and records it in Errors.
+	DecodeContinue
+)
+
+// DecodeError reports a document whose payload could not be decoded.
+type DecodeError struct {
+	ID  interface{}
+	Err error
+}
+
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("document %v: %v", e.ID, e.Err)
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ListDocumentsTyped is ListDocuments with each payload decoded into T,
+// under the client's decode limits. Documents without a payload get T's
+// zero value. Paging works as for ListDocuments.
+func ListDocumentsTyped[T any](ctx context.Context, c *Client, collection, cursor string, limit int, policy DecodePolicy, opts ...CallOption) (*TypedDocumentPage[T], error) {
+	page, err := c.ListDocuments(ctx, collection, cursor, limit, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &TypedDocumentPage[T]{
+		Documents:  make([]TypedDocument[T], 0, len(page.Documents)),
+		NextCursor: page.NextCursor,
+	}
+	for _, doc := range page.Documents {
+		typed := TypedDocument[T]{
+			ID:        plainID(doc.ID),
+			Vector:    doc.Vector,
+			Vectors:   doc.Vectors,
+			Version:   doc.Version,
+			DeletedAt: doc.DeletedAt,
+		}
+		if present(doc.Payload) {
+			if err := c.decodePayload(doc.Payload, &typed.Payload); err != nil {
+				decodeErr := DecodeError{ID: typed.ID, Err: err}
+				if policy == DecodeFailFast {
+					return nil, decodeErr
+				}
+				out.Errors = append(out.Errors, decodeErr)
+				continue
+			}
+		}
+		out.Documents = append(out.Documents, typed)
+	}
+	return out, nil
+}
@@ -0,0 +1,115 @@
+package barq
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ValidationReport is the result of ValidateBatch.
+type ValidationReport struct {
+	// Checked is the number of documents examined.
+	Checked int
+	// PayloadBytes is the total size of all payloads, a rough guide to how
+	// large the upload will be.
+	PayloadBytes int64
+	// Issues lists the documents with problems, in batch order.
+	Issues []ValidationIssue
+}
+
+// ValidationIssue describes everything wrong with one document of a batch.
+type ValidationIssue struct {
+	Index    int
+	ID       interface{}
+	Problems []string
+}
+
+// OK reports whether no document had problems.
+func (r *ValidationReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// ValidateBatch is a dry run for an ingest job: it checks docs against the
+// cached schema of collection without sending anything. It reports
+// duplicate IDs, vector dimensions, NaN or infinite vector components,
+// invalid payload JSON and missing required text fields. A nil ID is not a
+// problem, as the server assigns one, and dimensions are only checked when
+// the schema declares them. The schema must already be cached, e.g. by
+// DescribeCollection; otherwise ValidateBatch returns ErrSchemaNotCached.
+func (c *Client) ValidateBatch(collection string, docs []InsertRequest) (*ValidationReport, error) {
+	info, ok := c.meta.get(collection)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrSchemaNotCached, collection)
+	}
+
+	dims := make(map[string]int, len(info.Vectors))
+	for _, v := range info.Vectors {
+		dims[v.Name] = v.Dimension
+	}
+
+	report := &ValidationReport{Checked: len(docs)}
+	seen := make(map[string]int)
+	for i, doc := range docs {
+		var problems []string
+		report.PayloadBytes += int64(len(doc.Payload))
+
+		if doc.ID != nil {
+			key := idKey(doc.ID)
+			if first, dup := seen[key]; dup {
+				problems = append(problems, fmt.Sprintf("duplicate id, first seen at index %d", first))
+			} else {
+				seen[key] = i
+			}
+		}
+
+		if doc.Vector == nil && len(doc.Vectors) == 0 {
+			problems = append(problems, "missing vector")
+		}
+		if err := checkVectors(doc.Vector, doc.Vectors); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if doc.Vector != nil && info.Dimension > 0 && len(doc.Vector) != info.Dimension {
+			problems = append(problems, fmt.Sprintf("vector has %d dimensions, expected %d", len(doc.Vector), info.Dimension))
+		}
+		for name, v := range doc.Vectors {
+			dim, ok := dims[name]
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("unknown named vector %q", name))
+			case dim > 0 && len(v) != dim:
+				problems = append(problems, fmt.Sprintf("vector %q has %d dimensions, expected %d", name, len(v), dim))
+			}
+		}
+
+		if !opaquePayload(doc.PayloadContentType) {
+			problems = append(problems, payloadProblems(doc.Payload, info.TextFields)...)
+		}
+
+		if len(problems) > 0 {
+			report.Issues = append(report.Issues, ValidationIssue{Index: i, ID: doc.ID, Problems: problems})
+		}
+	}
+	return report, nil
+}
+
+func payloadProblems(payload json.RawMessage, textFields []TextField) []string {
+	var fields map[string]json.RawMessage
+	if len(payload) > 0 {
+		if !json.Valid(payload) {
+			return []string{"payload is not valid JSON"}
+		}
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return []string{"payload is not a JSON object"}
+		}
+	}
+
+	var problems []string
+	for _, f := range textFields {
+		if !f.Required {
+			continue
+		}
+		if v, ok := fields[f.Name]; !ok || string(v) == "null" {
+			problems = append(problems, fmt.Sprintf("missing required text field %q", f.Name))
+		}
+	}
+	return problems
+}
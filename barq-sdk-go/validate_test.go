@@ -0,0 +1,48 @@
+package barq
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateBatch(t *testing.T) {
+	c := NewClient(Config{BaseURL: "http://127.0.0.1:0"})
+	c.meta.set(&CollectionInfo{
+		Name:       "docs",
+		Dimension:  2,
+		TextFields: []TextField{{Name: "body", Required: true}},
+	})
+	report, err := c.ValidateBatch("docs", []InsertRequest{
+		{Vector: []float32{1, 2}, Payload: []byte(`{"body": "server assigns the id"}`)},
+		{Vector: []float32{1, 2}, Payload: []byte(`{"body": "nor this one"}`)},
+		{ID: 1, Vector: []float32{1, 2, 3}, Payload: []byte(`{"body": "x"}`)},
+		{ID: 1, Vector: []float32{1, 2}, Payload: []byte(`{}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Issues) != 2 {
+		t.Fatalf("got issues %+v, want the last two documents only", report.Issues)
+	}
+	if got := report.Issues[0]; got.Index != 2 || !strings.Contains(strings.Join(got.Problems, ";"), "3 dimensions, expected 2") {
+		t.Errorf("got %+v, want the dimension of document 2 flagged", got)
+	}
+	if got := strings.Join(report.Issues[1].Problems, ";"); !strings.Contains(got, "duplicate id") || !strings.Contains(got, `"body"`) {
+		t.Errorf("got %q, want the duplicate ID and the missing text field of document 3", got)
+	}
+}
+
+func TestValidateBatchUnknownDimension(t *testing.T) {
+	c := NewClient(Config{BaseURL: "http://127.0.0.1:0"})
+	c.meta.set(&CollectionInfo{Name: "docs", Vectors: []NamedVector{{Name: "title"}}})
+	report, err := c.ValidateBatch("docs", []InsertRequest{
+		{ID: 1, Vector: []float32{1, 2}, Vectors: map[string][]float32{"title": {1, 2, 3}}},
+		{ID: 2, Vector: []float32{1, 2, 3, 4}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.OK() {
+		t.Fatalf("got issues %+v for a schema declaring no dimensions", report.Issues)
+	}
+}
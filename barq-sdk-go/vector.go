@@ -0,0 +1,146 @@
+package barq
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// VectorEncodingBase64 is the vector_encoding value sent with inserts when
+// Config.BinaryVectors is set.
+const VectorEncodingBase64 = "f32le_base64"
+
+// EncodeVector packs v as little-endian IEEE 754 float32 values and returns
+// them base64 encoded (standard alphabet, padded).
+func EncodeVector(v []float32) string {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// DecodeVector reverses EncodeVector.
+func DecodeVector(s string) ([]float32, error) {
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("decode vector: %d bytes is not a multiple of 4", len(buf))
+	}
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v, nil
+}
+
+// checkVector rejects NaN and infinite components, which no distance
+// metric can use.
+func checkVector(v []float32) error {
+	for i, f := range v {
+		if math.IsNaN(float64(f)) {
+			return fmt.Errorf("%w: component %d is NaN", ErrInvalidVector, i)
+		}
+		if math.IsInf(float64(f), 0) {
+			return fmt.Errorf("%w: component %d is %v", ErrInvalidVector, i, f)
+		}
+	}
+	return nil
+}
+
+// checkVectors applies checkVector to a document's default and named
+// vectors.
+func checkVectors(vector []float32, named map[string][]float32) error {
+	if err := checkVector(vector); err != nil {
+		return err
+	}
+	for name, v := range named {
+		if err := checkVector(v); err != nil {
+			return fmt.Errorf("vector %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// insertWire is the JSON body of an insert: an InsertRequest plus fields
+// derived from it.
+type insertWire struct {
+	InsertRequest
+	// Payload is InsertRequest.Payload, or its bytes for an opaque
+	// payload, which encoding/json sends base64 encoded.
+	Payload    interface{} `json:"payload,omitempty"`
+	TTLSeconds int64       `json:"ttl_seconds,omitempty"`
+}
+
+// encodedInsert replaces the JSON float arrays of an insert with their
+// EncodeVector form.
+type encodedInsert struct {
+	insertWire
+	Vector         string            `json:"vector,omitempty"`
+	Vectors        map[string]string `json:"vectors,omitempty"`
+	VectorEncoding string            `json:"vector_encoding"`
+}
+
+// RoundVector returns a copy of v with each component rounded to digits
+// significant decimal digits, which shortens its JSON encoding. digits
+// below 1, or of 9 and more (full float32 precision), return v unchanged.
+func RoundVector(v []float32, digits int) []float32 {
+	if digits < 1 || digits >= 9 || v == nil {
+		return v
+	}
+	out := make([]float32, len(v))
+	for i, f := range v {
+		s := strconv.FormatFloat(float64(f), 'e', digits-1, 32)
+		r, _ := strconv.ParseFloat(s, 32)
+		out[i] = float32(r)
+	}
+	return out
+}
+
+func (c *Client) insertBody(req InsertRequest) interface{} {
+	wire := insertWire{InsertRequest: req, TTLSeconds: ttlSeconds(req.TTL)}
+	switch {
+	case opaquePayload(req.PayloadContentType):
+		wire.Payload = []byte(req.Payload)
+	case len(req.Payload) > 0:
+		wire.Payload = req.Payload
+	}
+	if !c.config.BinaryVectors {
+		if p := c.config.VectorPrecision; p > 0 {
+			wire.Vector = RoundVector(req.Vector, p)
+			if len(req.Vectors) > 0 {
+				wire.Vectors = make(map[string][]float32, len(req.Vectors))
+				for name, v := range req.Vectors {
+					wire.Vectors[name] = RoundVector(v, p)
+				}
+			}
+		}
+		return wire
+	}
+	body := encodedInsert{
+		insertWire:     wire,
+		Vector:         EncodeVector(req.Vector),
+		VectorEncoding: VectorEncodingBase64,
+	}
+	if len(req.Vectors) > 0 {
+		body.Vectors = make(map[string]string, len(req.Vectors))
+		for name, v := range req.Vectors {
+			body.Vectors[name] = EncodeVector(v)
+		}
+	}
+	return body
+}
+
+// ttlSeconds rounds a TTL up to whole seconds, so a short positive TTL never
+// becomes "no TTL".
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,59 @@
+package barq
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func TestEncodeVectorRoundTrip(t *testing.T) {
+	v := []float32{0, 1, -1.5, 3.1415927, 1e-38, -2e38}
+	got, err := DecodeVector(EncodeVector(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(v) {
+		t.Fatalf("got %d components, want %d", len(got), len(v))
+	}
+	for i := range v {
+		if got[i] != v[i] {
+			t.Errorf("component %d: got %v, want %v", i, got[i], v[i])
+		}
+	}
+}
+
+func TestDecodeVectorRejectsPartialFloat(t *testing.T) {
+	if _, err := DecodeVector("AAAA"); err == nil {
+		t.Fatal("decoded 3 bytes without error")
+	}
+}
+
+// benchmarkInsertBody measures encoding one insert of a 768-dimension
+// vector, and reports the body size alongside the time.
+func benchmarkInsertBody(b *testing.B, config Config) {
+	c := NewClient(config)
+	r := rand.New(rand.NewSource(1))
+	req := InsertRequest{ID: 1, Vector: make([]float32, 768)}
+	for i := range req.Vector {
+		req.Vector[i] = r.Float32()*2 - 1
+	}
+
+	b.ReportAllocs()
+	var size int
+	for i := 0; i < b.N; i++ {
+		data, err := json.Marshal(c.insertBody(req))
+		if err != nil {
+			b.Fatal(err)
+		}
+		size = len(data)
+	}
+	b.ReportMetric(float64(size), "body-bytes")
+}
+
+func BenchmarkInsertBodyJSON(b *testing.B) {
+	benchmarkInsertBody(b, Config{})
+}
+
+func BenchmarkInsertBodyBinary(b *testing.B) {
+	benchmarkInsertBody(b, Config{BinaryVectors: true})
+}
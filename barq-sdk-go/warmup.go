@@ -0,0 +1,71 @@
+package barq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// Warmup opens a connection to the server, doing the TLS handshake if
+// there is one, by calling GET /health, so the first real request does not
+// pay for connection setup. It is best effort: the connection stays in the
+// transport's idle pool only until IdleConnTimeout (90s by default), and
+// concurrent requests beyond it still open connections of their own. An
+// error means the server could not be reached or reported itself
+// unhealthy.
+func (c *Client) Warmup(ctx context.Context, opts ...CallOption) error {
+	_, err := c.request(ctx, "GET", "/health", nil, opts...)
+	return err
+}
+
+// keepWarm calls Warmup every interval until Close. Failures are ignored:
+// the next real request reports the problem itself.
+func (c *Client) keepWarm(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			c.Warmup(ctx)
+			cancel()
+		case <-c.stopKeepWarm:
+			return
+		}
+	}
+}
+
+// Close stops the Config.KeepWarmInterval pings and closes idle
+// connections. Requests still work after it, opening new connections; it
+// is only needed to release a client that keeps connections warm.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		if c.stopKeepWarm != nil {
+			close(c.stopKeepWarm)
+		}
+		c.http.CloseIdleConnections()
+	})
+	return nil
+}
+
+// Warmup asks the channel to connect and waits until it is Ready or ctx
+// ends. Like Client.Warmup it is best effort; the channel can still drop
+// the connection later, for example when the server goes away.
+func (c *GrpcClient) Warmup(ctx context.Context) error {
+	c.conn.Connect()
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return errors.New("barq: gRPC client is closed")
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("barq: gRPC channel not ready (%s): %w", state, ctx.Err())
+		}
+	}
+}
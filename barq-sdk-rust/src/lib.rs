@@ -294,6 +294,7 @@ impl BarqGrpcClient {
             id: id_str,
             vector,
             payload_json: payload.to_string(),
+            ..Default::default()
         }).await?;
         Ok(())
     }
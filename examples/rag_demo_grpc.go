@@ -38,13 +38,28 @@ func main() {
 	fmt.Println("Inserting document...")
 	vector := []float32{0.2, 0.8}
 	payload := map[string]string{"lang": "go", "protocol": "grpc"}
-	err = client.InsertDocument(ctx, "grpc_go_rag", "doc_go_1", vector, payload)
+	// Wait until the document is searchable before querying it. Servers
+	// that do not advertise wait_for_index ignore the flag, so fall back
+	// to giving the index a moment.
+	status, err := client.HealthDetailed(ctx)
+	if err != nil {
+		log.Fatalf("Health check failed: %v", err)
+	}
+	waitForIndex := false
+	for _, f := range status.Features {
+		waitForIndex = waitForIndex || f == "wait_for_index"
+	}
+	var opts []barq.InsertOption
+	if waitForIndex {
+		opts = append(opts, barq.WaitForIndex())
+	}
+	err = client.InsertDocument(ctx, "grpc_go_rag", "doc_go_1", vector, payload, opts...)
 	if err != nil {
 		log.Fatalf("Insert failed: %v", err)
 	}
-
-	// Wait
-	time.Sleep(500 * time.Millisecond)
+	if !waitForIndex {
+		time.Sleep(500 * time.Millisecond)
+	}
 
 	// 5. Search
 	fmt.Println("Searching...")